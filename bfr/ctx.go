@@ -10,7 +10,9 @@ import (
 // Ctx is serialization context with output configuration flags
 type Ctx struct {
 	B
-	JSON  bool
+	JSON bool
+	// Tuple writes records as lists in field order omitting trailing zero optional fields.
+	Tuple bool
 	Depth int
 	Tab   string
 }
@@ -0,0 +1,105 @@
+package bfr
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// DefaultLimit is the default number of bytes a stream buffers before it flushes.
+const DefaultLimit = 32 << 10
+
+// Stream is a buffered writer implementing B, that writes to an underlying io.Writer every time
+// the buffered output grows past a limit. If the writer has a Flush method, for example a
+// http.ResponseWriter or bufio.Writer, it is called after each write.
+//
+// The first error is kept and returned by all subsequent writes and by Flush, so that writer
+// implementations that do not check every write error still fail.
+type Stream struct {
+	w     io.Writer
+	buf   []byte
+	limit int
+	err   error
+}
+
+// NewStream returns a new stream writing to w flushing after limit bytes or DefaultLimit if
+// limit is zero or less.
+func NewStream(w io.Writer, limit int) *Stream {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Stream{w: w, buf: make([]byte, 0, limit+utf8.UTFMax), limit: limit}
+}
+
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.buf = append(s.buf, p...)
+	return len(p), s.check()
+}
+
+func (s *Stream) WriteByte(c byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.buf = append(s.buf, c)
+	return s.check()
+}
+
+func (s *Stream) WriteRune(r rune) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	var tmp [utf8.UTFMax]byte
+	n := utf8.EncodeRune(tmp[:], r)
+	s.buf = append(s.buf, tmp[:n]...)
+	return n, s.check()
+}
+
+func (s *Stream) WriteString(str string) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.buf = append(s.buf, str...)
+	return len(str), s.check()
+}
+
+// Flush writes all buffered output to the underlying writer and flushes it if possible.
+func (s *Stream) Flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.buf) > 0 {
+		_, s.err = s.w.Write(s.buf)
+		s.buf = s.buf[:0]
+		if s.err != nil {
+			return s.err
+		}
+	}
+	switch f := s.w.(type) {
+	case interface{ Flush() error }:
+		s.err = f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return s.err
+}
+
+func (s *Stream) check() error {
+	if len(s.buf) < s.limit {
+		return nil
+	}
+	return s.Flush()
+}
+
+// WriteTo writes w with the flags of c to the writer out and returns an error.
+// The output is flushed periodically while w is written and once at the end.
+func WriteTo(out io.Writer, w Writer, c Ctx) error {
+	s := NewStream(out, 0)
+	c.B = s
+	err := w.WriteBfr(&c)
+	if err != nil {
+		return err
+	}
+	return s.Flush()
+}
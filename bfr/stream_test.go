@@ -0,0 +1,48 @@
+package bfr
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type countWriter struct {
+	strings.Builder
+	writes, flushes int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Builder.Write(p)
+}
+func (w *countWriter) Flush() { w.flushes++ }
+
+// numList writes a list of numbers from zero to n.
+type numList int
+
+func (n numList) WriteBfr(b *Ctx) error {
+	b.WriteByte('[')
+	for i := 0; i < int(n); i++ {
+		if i > 0 {
+			b.Sep()
+		}
+		b.WriteString(strconv.Itoa(i))
+	}
+	return b.WriteByte(']')
+}
+
+func TestWriteTo(t *testing.T) {
+	l := numList(20000)
+	var w countWriter
+	err := WriteTo(&w, l, Ctx{JSON: true})
+	if err != nil {
+		t.Fatalf("write err: %v", err)
+	}
+	want, _ := JSON(l)
+	if got := w.String(); got != string(want) {
+		t.Errorf("want streamed output to equal json output")
+	}
+	if w.writes < 2 || w.flushes != w.writes {
+		t.Errorf("want periodic flushes got %d writes %d flushes", w.writes, w.flushes)
+	}
+}
@@ -0,0 +1,596 @@
+// Package gen generates literal proxies for go struct types that do not use reflection.
+//
+// The generated proxies implement lit.Proxy and lit.Record and register themselves with the prx
+// package, so that prx.NewProxy and prx.ProxyValue use them instead of the reflection based
+// proxies. They produce the same types and output and respect the xelf struct tags. Fields of
+// primitive types, of other generated types and of slices and string maps of those are accessed
+// directly, all other fields use reflection. Generated identifiers use the _xelf prefix.
+//
+// The generator itself uses reflection and is usually called from a small program that imports
+// the package with the hot types and is run with go generate:
+//     err := gen.Proxies(f, "mypkg", mypkg.Order{}, mypkg.Item{})
+package gen
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/prx"
+	"github.com/mb0/xelf/typ"
+)
+
+// Proxies writes go source code of package pkg with proxies for the struct types of vals to w.
+// All types must be named struct types declared in the same package.
+func Proxies(w io.Writer, pkg string, vals ...interface{}) error {
+	g := &generator{Pkg: pkg, names: make(map[reflect.Type]string)}
+	ts := make([]reflect.Type, 0, len(vals))
+	for _, v := range vals {
+		t := reflect.TypeOf(v)
+		if t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+			return cor.Errorf("gen expects named struct type got %T", v)
+		}
+		if g.path == "" {
+			g.path = t.PkgPath()
+		} else if g.path != t.PkgPath() {
+			return cor.Errorf("gen expects types of package %s got %s", g.path, t)
+		}
+		g.names[t] = t.Name()
+		ts = append(ts, t)
+	}
+	for _, t := range ts {
+		gt, err := g.genType(t)
+		if err != nil {
+			return err
+		}
+		g.Types = append(g.Types, gt)
+	}
+	var b bytes.Buffer
+	err := tmpl.Execute(&b, g)
+	if err != nil {
+		return err
+	}
+	res, err := format.Source(b.Bytes())
+	if err != nil {
+		return cor.Errorf("gen format source: %w", err)
+	}
+	_, err = w.Write(res)
+	return err
+}
+
+type generator struct {
+	Pkg   string
+	Time  bool
+	Types []*genType
+	Conts []*genCont
+	path  string
+	names map[reflect.Type]string
+}
+
+type genType struct {
+	Name     string
+	Fields   []genField
+	Direct   []int
+	ZeroProx bool
+}
+
+type genField struct {
+	Key   string
+	Sel   string
+	Cast  string
+	Proxy string
+	Zero  string
+}
+
+// genCont is a list or dict proxy for a go slice or map type with directly accessible elements.
+type genCont struct {
+	Name string
+	Type string
+	Elem string
+	Dict bool
+	At   string
+	Of   string
+	New  string
+}
+
+func (g *generator) genType(t reflect.Type) (*genType, error) {
+	rt, idx, err := prx.ReflectFields(t)
+	if err != nil {
+		return nil, cor.Errorf("gen type %s: %w", t, err)
+	}
+	res := &genType{Name: t.Name(), Fields: make([]genField, 0, len(idx))}
+	for i, p := range rt.Params {
+		var sel []string
+		ft := t
+		for j, x := range idx[i] {
+			if j > 0 && ft.Kind() == reflect.Ptr {
+				return nil, cor.Errorf("gen type %s: embedded pointer in field %s", t, p.Key())
+			}
+			f := ft.Field(x)
+			sel = append(sel, f.Name)
+			ft = f.Type
+		}
+		f := genField{Key: p.Key(), Sel: strings.Join(sel, "."), Cast: adapter(ft, p.Type)}
+		if _, acc := g.access(ft, p.Type); acc != "" {
+			f.Proxy = strings.NewReplacer("$ptr", "&p.v."+f.Sel,
+				"$typ", "_xelfTyp"+res.Name+".Params["+strconv.Itoa(i)+"].Type").Replace(acc)
+			res.Direct = append(res.Direct, i)
+		}
+		f.Zero = zeroCheck(f, ft, i)
+		res.ZeroProx = res.ZeroProx || strings.HasPrefix(f.Zero, "p.zeroField")
+		res.Fields = append(res.Fields, f)
+	}
+	return res, nil
+}
+
+// access returns a name and a go expression template for a proxy of go type t and xelf type ft
+// that does not use reflection, or empty strings. The template uses $ptr for the value pointer
+// and $typ for the xelf type. Containers of accessible elements add a container proxy to g.
+func (g *generator) access(t reflect.Type, ft typ.Type) (name, acc string) {
+	if c := adapter(t, ft); c != "" {
+		return strings.TrimPrefix(c, "lit."), "(*" + c + ")($ptr)"
+	}
+	if n, ok := g.names[t]; ok {
+		if rt, _, err := prx.ReflectFields(t); err == nil && rt.Equal(ft) {
+			return n, "&_xelfProxy" + n + "{$ptr}"
+		}
+		return "", ""
+	}
+	var c genCont
+	switch {
+	case t.Kind() == reflect.Slice && ft.Kind == typ.KindList:
+		c.Name = "List"
+	case t.Kind() == reflect.Map && t.Key() == refStr && ft.Kind == typ.KindDict:
+		c.Name, c.Dict = "Dict", true
+	default:
+		return "", ""
+	}
+	en, ea := g.access(t.Elem(), ft.Elem())
+	if ea == "" {
+		return "", ""
+	}
+	c.Name += en
+	acc = "&_xelf" + c.Name + "{$typ, $ptr}"
+	for _, o := range g.Conts {
+		if o.Name == c.Name {
+			return c.Name, acc
+		}
+	}
+	c.Type, c.Elem = g.goType(t), g.goType(t.Elem())
+	r := func(ptr string) string {
+		return strings.NewReplacer("$ptr", ptr, "$typ", "p.t.Elem()").Replace(ea)
+	}
+	c.At, c.Of, c.New = r("&(*p.v)[i]"), r("&e"), r("new("+c.Elem+")")
+	g.Conts = append(g.Conts, &c)
+	return c.Name, acc
+}
+
+// goType returns the go source of type t as used in the generated package.
+func (g *generator) goType(t reflect.Type) string {
+	switch {
+	case t.Name() == "":
+		switch t.Kind() {
+		case reflect.Slice:
+			return "[]" + g.goType(t.Elem())
+		case reflect.Map:
+			return "map[" + g.goType(t.Key()) + "]" + g.goType(t.Elem())
+		}
+	case t.PkgPath() == g.path:
+		return t.Name()
+	case t.PkgPath() == "time":
+		g.Time = true
+	}
+	return t.String()
+}
+
+var refStr = reflect.TypeOf("")
+
+// zeroCheck returns a go expression reporting whether field f of go type t at index i is zero.
+// Fields without a simple check use the IsZero method of the field proxy.
+func zeroCheck(f genField, t reflect.Type, i int) string {
+	v := "p.v." + f.Sel
+	if f.Cast != "" {
+		return f.Cast + "(" + v + ").IsZero()"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "!" + v
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v + " == 0"
+	case reflect.String:
+		return v + ` == ""`
+	case reflect.Slice, reflect.Map:
+		return "len(" + v + ") == 0"
+	case reflect.Ptr, reflect.Interface:
+		return v + " == nil"
+	}
+	if f.Proxy != "" {
+		return "(" + f.Proxy + ").IsZero()"
+	}
+	return "p.zeroField(" + strconv.Itoa(i) + ")"
+}
+
+var adapters = []struct {
+	ref reflect.Type
+	typ typ.Type
+	lit string
+}{
+	{reflect.TypeOf(false), typ.Bool, "lit.Bool"},
+	{reflect.TypeOf(int64(0)), typ.Int, "lit.Int"},
+	{reflect.TypeOf(float64(0)), typ.Real, "lit.Real"},
+	{refStr, typ.Str, "lit.Str"},
+	{reflect.TypeOf([]byte(nil)), typ.Raw, "lit.Raw"},
+	{reflect.TypeOf([16]byte{}), typ.UUID, "lit.UUID"},
+	{reflect.TypeOf(time.Time{}), typ.Time, "lit.Time"},
+	{reflect.TypeOf(time.Duration(0)), typ.Span, "lit.Span"},
+}
+
+// adapter returns the literal adapter type for fields of go type t and xelf type ft or an empty
+// string, if the field needs a proxy.
+func adapter(t reflect.Type, ft typ.Type) string {
+	for _, a := range adapters {
+		if t == a.ref && ft.Equal(a.typ) {
+			return a.lit
+		}
+	}
+	return ""
+}
+
+var tmpl = template.Must(template.New("gen").Parse(`// Code generated by xelf gen. DO NOT EDIT.
+
+package {{ .Pkg }}
+
+import (
+{{- if .Time }}
+	"time"
+{{ end }}
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+	"github.com/mb0/xelf/typ"
+)
+{{ range .Types }}{{ $n := .Name }}
+var _xelfTyp{{ $n }} typ.Type
+var _xelfKeys{{ $n }} = []string{ {{- range $i, $f := .Fields }}{{ if $i }}, {{ end }}{{ printf "%q" $f.Key }}{{ end -}} }
+
+func init() {
+	t, err := prx.Reflect({{ $n }}{})
+	if err != nil {
+		panic(err)
+	}
+	_xelfTyp{{ $n }} = t
+	prx.Register((*{{ $n }})(nil), func(ptr interface{}) lit.Proxy {
+		return &_xelfProxy{{ $n }}{ptr.(*{{ $n }})}
+	})
+}
+
+type _xelfProxy{{ $n }} struct{ v *{{ $n }} }
+
+func (p *_xelfProxy{{ $n }}) Typ() typ.Type                { return _xelfTyp{{ $n }} }
+func (p *_xelfProxy{{ $n }}) New() lit.Proxy               { return &_xelfProxy{{ $n }}{new({{ $n }})} }
+func (p *_xelfProxy{{ $n }}) Ptr() interface{}             { return p.v }
+func (p *_xelfProxy{{ $n }}) Len() int                     { return {{ len .Fields }} }
+func (p *_xelfProxy{{ $n }}) Keys() []string               { return append([]string(nil), _xelfKeys{{ $n }}...) }
+func (p *_xelfProxy{{ $n }}) String() string               { return bfr.String(p) }
+func (p *_xelfProxy{{ $n }}) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+
+func (p *_xelfProxy{{ $n }}) IsZero() bool {
+	if p.v == nil {
+		return true
+	}
+	return {{ range $i, $f := .Fields }}{{ if $i }} &&
+		{{ end }}{{ $f.Zero }}{{ else }}true{{ end }}
+}
+{{ if .ZeroProx }}
+func (p *_xelfProxy{{ $n }}) zeroField(i int) bool {
+	el, err := p.field(i)
+	return err != nil || el.IsZero()
+}
+{{ end }}
+func (p *_xelfProxy{{ $n }}) WriteBfr(b *bfr.Ctx) error {
+	if p.v == nil {
+		return prx.WriteFields(b, _xelfTyp{{ $n }}, nil)
+	}
+	return prx.WriteFields(b, _xelfTyp{{ $n }}, p.field)
+}
+
+func (p *_xelfProxy{{ $n }}) field(i int) (lit.Proxy, error) {
+	if p.v == nil {
+		return nil, prx.ErrNotStruct
+	}
+	switch i {
+{{- range $i, $f := .Fields }}
+	case {{ $i }}:
+{{- if $f.Proxy }}
+		return {{ $f.Proxy }}, nil
+{{- else }}
+		return prx.FieldProxy(&p.v.{{ $f.Sel }}, _xelfTyp{{ $n }}.Params[{{ $i }}].Type)
+{{- end }}
+{{- end }}
+	}
+	return nil, lit.ErrIdxBounds
+}
+
+func (p *_xelfProxy{{ $n }}) keyIdx(k string) (int, error) {
+	switch k {
+{{- range $i, $f := .Fields }}
+	case {{ printf "%q" $f.Key }}:
+		return {{ $i }}, nil
+{{- end }}
+	}
+	_, i, err := _xelfTyp{{ $n }}.ParamByKey(k)
+	return i, err
+}
+
+func (p *_xelfProxy{{ $n }}) Idx(i int) (lit.Lit, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return nil, err
+	}
+{{- if .Direct }}
+	switch i {
+	case {{ range $j, $i := .Direct }}{{ if $j }}, {{ end }}{{ $i }}{{ end }}:
+		return el, nil // direct proxies already have the field type
+	}
+{{- end }}
+	return lit.Convert(el, _xelfTyp{{ $n }}.Params[i].Type, 0)
+}
+
+func (p *_xelfProxy{{ $n }}) Key(k string) (lit.Lit, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return nil, err
+	}
+	return p.Idx(i)
+}
+
+func (p *_xelfProxy{{ $n }}) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return p, err
+	}
+	return p, prx.AssignTo(l, el)
+}
+
+func (p *_xelfProxy{{ $n }}) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return p, err
+	}
+	_, err = p.SetIdx(i, l)
+	return p, err
+}
+
+func (p *_xelfProxy{{ $n }}) IterIdx(it func(int, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i := range _xelfKeys{{ $n }} {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(i, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxy{{ $n }}) IterKey(it func(string, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i, k := range _xelfKeys{{ $n }} {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(k, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxy{{ $n }}) Assign(l lit.Lit) error {
+	if l == nil || !_xelfTyp{{ $n }}.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, _xelfTyp{{ $n }})
+	}
+	if p.v == nil {
+		return prx.ErrNotStruct
+	}
+	b, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || b.IsZero() {
+		*p.v = {{ $n }}{}
+		return nil
+	}
+	return b.IterKey(func(k string, e lit.Lit) error {
+		i, err := p.keyIdx(k)
+		if err != nil {
+			return err
+		}
+		el, err := p.field(i)
+		if err != nil {
+			return err
+		}
+		return el.Assign(e)
+	})
+}
+{{ end }}
+{{- range .Conts }}{{ $n := printf "_xelf%s" .Name }}
+type {{ $n }} struct {
+	t typ.Type
+	v *{{ .Type }}
+}
+
+func (p *{{ $n }}) Typ() typ.Type                { return p.t }
+func (p *{{ $n }}) New() lit.Proxy               { return &{{ $n }}{p.t, new({{ .Type }})} }
+func (p *{{ $n }}) Ptr() interface{}             { return p.v }
+func (p *{{ $n }}) Len() int                     { return len(*p.v) }
+func (p *{{ $n }}) IsZero() bool                 { return len(*p.v) == 0 }
+func (p *{{ $n }}) String() string               { return bfr.String(p) }
+func (p *{{ $n }}) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+func (p *{{ $n }}) Element() (lit.Proxy, error)  { return {{ .New }}, nil }
+{{ if .Dict }}
+func (p *{{ $n }}) WriteBfr(b *bfr.Ctx) error { return prx.WriteDict(b, p) }
+
+func (p *{{ $n }}) Keys() []string {
+	res := make([]string, 0, len(*p.v))
+	for k := range *p.v {
+		res = append(res, k)
+	}
+	return res
+}
+
+func (p *{{ $n }}) Key(k string) (lit.Lit, error) {
+	e, ok := (*p.v)[k]
+	if !ok {
+		return lit.Nil, nil
+	}
+	return {{ .Of }}, nil
+}
+
+func (p *{{ $n }}) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	var e {{ .Elem }}
+	err := prx.AssignTo(l, {{ .Of }})
+	if err != nil {
+		return p, err
+	}
+	if *p.v == nil {
+		*p.v = make({{ .Type }})
+	}
+	(*p.v)[k] = e
+	return p, nil
+}
+
+func (p *{{ $n }}) Delete(k string) bool {
+	_, ok := (*p.v)[k]
+	if ok {
+		delete(*p.v, k)
+	}
+	return ok
+}
+
+func (p *{{ $n }}) IterKey(it func(string, lit.Lit) error) error {
+	for k, e := range *p.v {
+		e := e
+		if err := it(k, {{ .Of }}); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *{{ $n }}) Assign(l lit.Lit) error {
+	if l == nil || !p.t.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, p.t)
+	}
+	b, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || b.IsZero() {
+		*p.v = nil
+		return nil
+	}
+	if *p.v == nil {
+		*p.v = make({{ .Type }}, b.Len())
+	}
+	return b.IterKey(func(k string, l lit.Lit) error {
+		var e {{ .Elem }}
+		err := ({{ .Of }}).Assign(l)
+		if err != nil {
+			return err
+		}
+		(*p.v)[k] = e
+		return nil
+	})
+}
+{{ else }}
+func (p *{{ $n }}) WriteBfr(b *bfr.Ctx) error { return prx.WriteList(b, p) }
+
+func (p *{{ $n }}) Idx(i int) (lit.Lit, error) {
+	if i < 0 || i >= len(*p.v) {
+		return nil, lit.ErrIdxBounds
+	}
+	return {{ .At }}, nil
+}
+
+func (p *{{ $n }}) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	if i < 0 || i >= len(*p.v) {
+		return p, lit.ErrIdxBounds
+	}
+	return p, prx.AssignTo(l, {{ .At }})
+}
+
+func (p *{{ $n }}) IterIdx(it func(int, lit.Lit) error) error {
+	for i := range *p.v {
+		if err := it(i, {{ .At }}); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *{{ $n }}) Append(ls ...lit.Lit) (lit.Appender, error) {
+	v := *p.v
+	for _, l := range ls {
+		var e {{ .Elem }}
+		err := prx.AssignTo(l, {{ .Of }})
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, e)
+	}
+	return &{{ $n }}{p.t, &v}, nil
+}
+
+func (p *{{ $n }}) Assign(l lit.Lit) error {
+	if l == nil || !p.t.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, p.t)
+	}
+	b, ok := lit.Deopt(l).(lit.Indexer)
+	if !ok || b.IsZero() {
+		*p.v = nil
+		return nil
+	}
+	v := (*p.v)[:0]
+	err := b.IterIdx(func(i int, l lit.Lit) error {
+		var e {{ .Elem }}
+		err := ({{ .Of }}).Assign(l)
+		if err != nil {
+			return err
+		}
+		v = append(v, e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*p.v = v
+	return nil
+}
+{{ end }}
+{{- end }}`))
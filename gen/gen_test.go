@@ -0,0 +1,186 @@
+package gen
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+)
+
+var update = flag.Bool("update", false, "update the generated golden file")
+
+const golden = "proxy_gen_test.go"
+
+type genItem struct {
+	Name  string
+	Qty   int64
+	Price float64
+}
+
+type genOrder struct {
+	ID     int64
+	Title  string `xelf:"title,doc=The order title"`
+	Status string `xelf:",enum"`
+	Items  []genItem
+	Tags   map[string]string
+	Due    time.Time
+	Count  int
+	Note   string `json:",omitempty"`
+	Ship   genItem
+}
+
+// reflItem and reflOrder mirror the generated types and use the reflection based proxies.
+type reflItem struct {
+	Name  string
+	Qty   int64
+	Price float64
+}
+
+type reflOrder struct {
+	ID     int64
+	Title  string `xelf:"title,doc=The order title"`
+	Status string `xelf:",enum"`
+	Items  []reflItem
+	Tags   map[string]string
+	Due    time.Time
+	Count  int
+	Note   string `json:",omitempty"`
+	Ship   reflItem
+}
+
+func TestProxies(t *testing.T) {
+	var b bytes.Buffer
+	err := Proxies(&b, "gen", genOrder{}, genItem{})
+	if err != nil {
+		t.Fatalf("gen err: %v", err)
+	}
+	if *update {
+		err = ioutil.WriteFile(golden, b.Bytes(), 0644)
+		if err != nil {
+			t.Fatalf("update golden err: %v", err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden err: %v", err)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("generated code differs from %s, run go test -update", golden)
+	}
+}
+
+const orderSrc = `{id:7 title:'first' status:'open' items:[{name:'a' qty:2 price:1.5}]
+	tags:{x:'y'} due:'2020-02-02T12:00:00Z' count:3}`
+
+func TestGenProxy(t *testing.T) {
+	var g genOrder
+	gp, err := prx.NewProxy(&g)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	if _, ok := gp.(*_xelfProxygenOrder); !ok {
+		t.Fatalf("want generated proxy got %T", gp)
+	}
+	if !gp.IsZero() {
+		t.Errorf("want zero order proxy")
+	}
+	g.Ship.Qty = 1
+	if gp.IsZero() {
+		t.Errorf("want order proxy with ship qty to be non zero")
+	}
+	g.Ship.Qty = 0
+	var r reflOrder
+	rp, err := prx.NewProxy(&r)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	if !gp.Typ().Equal(rp.Typ()) {
+		t.Errorf("want type %s got %s", rp.Typ(), gp.Typ())
+	}
+	l, err := lit.Read(strings.NewReader(orderSrc))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	for _, p := range []lit.Proxy{gp, rp} {
+		err = prx.AssignTo(l, p)
+		if err != nil {
+			t.Fatalf("assign to %T err: %v", p, err)
+		}
+	}
+	if g.ID != 7 || g.Title != "first" || len(g.Items) != 1 || g.Items[0].Qty != 2 ||
+		g.Tags["x"] != "y" || g.Count != 3 {
+		t.Errorf("unexpected order %+v", g)
+	}
+	if gs, rs := gp.String(), rp.String(); gs != rs {
+		t.Errorf("want %s got %s", rs, gs)
+	}
+	if !lit.Equal(gp, rp) {
+		t.Errorf("want generated and reflect proxy equal")
+	}
+	k := gp.(lit.Keyer)
+	_, err = k.SetKey("title", lit.Str("second"))
+	if err != nil || g.Title != "second" {
+		t.Errorf("set key title got %q err %v", g.Title, err)
+	}
+	if _, err = k.Key("unknown"); err == nil {
+		t.Errorf("want error for unknown key")
+	}
+	el, err := k.Key("items")
+	if err != nil {
+		t.Fatalf("key items err: %v", err)
+	}
+	item, err := lit.Read(strings.NewReader(`{name:'b' qty:1 price:2}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	items, err := el.(lit.Appender).Append(item)
+	if err != nil || items.Len() != 2 || len(g.Items) != 1 {
+		t.Errorf("append item got %v err %v", items, err)
+	}
+	_, err = items.SetIdx(0, item)
+	if got := items.String(); err != nil || got != `[{name:'b' qty:1 price:2} {name:'b' qty:1 price:2}]` {
+		t.Errorf("set idx got %s err %v", got, err)
+	}
+	el, err = k.Key("tags")
+	if err != nil {
+		t.Fatalf("key tags err: %v", err)
+	}
+	tags := el.(lit.Deleter)
+	_, err = tags.SetKey("z", lit.Str("w"))
+	if err != nil || g.Tags["z"] != "w" {
+		t.Errorf("set tag got %v err %v", g.Tags, err)
+	}
+	if !tags.Delete("x") || len(g.Tags) != 1 {
+		t.Errorf("delete tag got %v", g.Tags)
+	}
+}
+
+func benchProxy(b *testing.B, ptr interface{}) {
+	l, err := lit.Read(strings.NewReader(orderSrc))
+	if err != nil {
+		b.Fatalf("read err: %v", err)
+	}
+	for i := 0; i < b.N; i++ {
+		p, err := prx.NewProxy(ptr)
+		if err != nil {
+			b.Fatal(err)
+		}
+		err = prx.AssignTo(l, p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		err = p.(lit.Keyer).IterKey(func(string, lit.Lit) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = p.String()
+	}
+}
+
+func BenchmarkProxyGen(b *testing.B)     { benchProxy(b, &genOrder{}) }
+func BenchmarkProxyReflect(b *testing.B) { benchProxy(b, &reflOrder{}) }
@@ -0,0 +1,547 @@
+// Code generated by xelf gen. DO NOT EDIT.
+
+package gen
+
+import (
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+	"github.com/mb0/xelf/typ"
+)
+
+var _xelfTypgenOrder typ.Type
+var _xelfKeysgenOrder = []string{"id", "title", "status", "items", "tags", "due", "count", "note", "ship"}
+
+func init() {
+	t, err := prx.Reflect(genOrder{})
+	if err != nil {
+		panic(err)
+	}
+	_xelfTypgenOrder = t
+	prx.Register((*genOrder)(nil), func(ptr interface{}) lit.Proxy {
+		return &_xelfProxygenOrder{ptr.(*genOrder)}
+	})
+}
+
+type _xelfProxygenOrder struct{ v *genOrder }
+
+func (p *_xelfProxygenOrder) Typ() typ.Type                { return _xelfTypgenOrder }
+func (p *_xelfProxygenOrder) New() lit.Proxy               { return &_xelfProxygenOrder{new(genOrder)} }
+func (p *_xelfProxygenOrder) Ptr() interface{}             { return p.v }
+func (p *_xelfProxygenOrder) Len() int                     { return 9 }
+func (p *_xelfProxygenOrder) Keys() []string               { return append([]string(nil), _xelfKeysgenOrder...) }
+func (p *_xelfProxygenOrder) String() string               { return bfr.String(p) }
+func (p *_xelfProxygenOrder) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+
+func (p *_xelfProxygenOrder) IsZero() bool {
+	if p.v == nil {
+		return true
+	}
+	return lit.Int(p.v.ID).IsZero() &&
+		lit.Str(p.v.Title).IsZero() &&
+		p.v.Status == "" &&
+		len(p.v.Items) == 0 &&
+		len(p.v.Tags) == 0 &&
+		lit.Time(p.v.Due).IsZero() &&
+		p.v.Count == 0 &&
+		lit.Str(p.v.Note).IsZero() &&
+		(&_xelfProxygenItem{&p.v.Ship}).IsZero()
+}
+
+func (p *_xelfProxygenOrder) WriteBfr(b *bfr.Ctx) error {
+	if p.v == nil {
+		return prx.WriteFields(b, _xelfTypgenOrder, nil)
+	}
+	return prx.WriteFields(b, _xelfTypgenOrder, p.field)
+}
+
+func (p *_xelfProxygenOrder) field(i int) (lit.Proxy, error) {
+	if p.v == nil {
+		return nil, prx.ErrNotStruct
+	}
+	switch i {
+	case 0:
+		return (*lit.Int)(&p.v.ID), nil
+	case 1:
+		return (*lit.Str)(&p.v.Title), nil
+	case 2:
+		return prx.FieldProxy(&p.v.Status, _xelfTypgenOrder.Params[2].Type)
+	case 3:
+		return &_xelfListgenItem{_xelfTypgenOrder.Params[3].Type, &p.v.Items}, nil
+	case 4:
+		return &_xelfDictStr{_xelfTypgenOrder.Params[4].Type, &p.v.Tags}, nil
+	case 5:
+		return (*lit.Time)(&p.v.Due), nil
+	case 6:
+		return prx.FieldProxy(&p.v.Count, _xelfTypgenOrder.Params[6].Type)
+	case 7:
+		return (*lit.Str)(&p.v.Note), nil
+	case 8:
+		return &_xelfProxygenItem{&p.v.Ship}, nil
+	}
+	return nil, lit.ErrIdxBounds
+}
+
+func (p *_xelfProxygenOrder) keyIdx(k string) (int, error) {
+	switch k {
+	case "id":
+		return 0, nil
+	case "title":
+		return 1, nil
+	case "status":
+		return 2, nil
+	case "items":
+		return 3, nil
+	case "tags":
+		return 4, nil
+	case "due":
+		return 5, nil
+	case "count":
+		return 6, nil
+	case "note":
+		return 7, nil
+	case "ship":
+		return 8, nil
+	}
+	_, i, err := _xelfTypgenOrder.ParamByKey(k)
+	return i, err
+}
+
+func (p *_xelfProxygenOrder) Idx(i int) (lit.Lit, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return nil, err
+	}
+	switch i {
+	case 0, 1, 3, 4, 5, 7, 8:
+		return el, nil // direct proxies already have the field type
+	}
+	return lit.Convert(el, _xelfTypgenOrder.Params[i].Type, 0)
+}
+
+func (p *_xelfProxygenOrder) Key(k string) (lit.Lit, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return nil, err
+	}
+	return p.Idx(i)
+}
+
+func (p *_xelfProxygenOrder) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return p, err
+	}
+	return p, prx.AssignTo(l, el)
+}
+
+func (p *_xelfProxygenOrder) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return p, err
+	}
+	_, err = p.SetIdx(i, l)
+	return p, err
+}
+
+func (p *_xelfProxygenOrder) IterIdx(it func(int, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i := range _xelfKeysgenOrder {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(i, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxygenOrder) IterKey(it func(string, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i, k := range _xelfKeysgenOrder {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(k, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxygenOrder) Assign(l lit.Lit) error {
+	if l == nil || !_xelfTypgenOrder.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, _xelfTypgenOrder)
+	}
+	if p.v == nil {
+		return prx.ErrNotStruct
+	}
+	b, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || b.IsZero() {
+		*p.v = genOrder{}
+		return nil
+	}
+	return b.IterKey(func(k string, e lit.Lit) error {
+		i, err := p.keyIdx(k)
+		if err != nil {
+			return err
+		}
+		el, err := p.field(i)
+		if err != nil {
+			return err
+		}
+		return el.Assign(e)
+	})
+}
+
+var _xelfTypgenItem typ.Type
+var _xelfKeysgenItem = []string{"name", "qty", "price"}
+
+func init() {
+	t, err := prx.Reflect(genItem{})
+	if err != nil {
+		panic(err)
+	}
+	_xelfTypgenItem = t
+	prx.Register((*genItem)(nil), func(ptr interface{}) lit.Proxy {
+		return &_xelfProxygenItem{ptr.(*genItem)}
+	})
+}
+
+type _xelfProxygenItem struct{ v *genItem }
+
+func (p *_xelfProxygenItem) Typ() typ.Type                { return _xelfTypgenItem }
+func (p *_xelfProxygenItem) New() lit.Proxy               { return &_xelfProxygenItem{new(genItem)} }
+func (p *_xelfProxygenItem) Ptr() interface{}             { return p.v }
+func (p *_xelfProxygenItem) Len() int                     { return 3 }
+func (p *_xelfProxygenItem) Keys() []string               { return append([]string(nil), _xelfKeysgenItem...) }
+func (p *_xelfProxygenItem) String() string               { return bfr.String(p) }
+func (p *_xelfProxygenItem) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+
+func (p *_xelfProxygenItem) IsZero() bool {
+	if p.v == nil {
+		return true
+	}
+	return lit.Str(p.v.Name).IsZero() &&
+		lit.Int(p.v.Qty).IsZero() &&
+		lit.Real(p.v.Price).IsZero()
+}
+
+func (p *_xelfProxygenItem) WriteBfr(b *bfr.Ctx) error {
+	if p.v == nil {
+		return prx.WriteFields(b, _xelfTypgenItem, nil)
+	}
+	return prx.WriteFields(b, _xelfTypgenItem, p.field)
+}
+
+func (p *_xelfProxygenItem) field(i int) (lit.Proxy, error) {
+	if p.v == nil {
+		return nil, prx.ErrNotStruct
+	}
+	switch i {
+	case 0:
+		return (*lit.Str)(&p.v.Name), nil
+	case 1:
+		return (*lit.Int)(&p.v.Qty), nil
+	case 2:
+		return (*lit.Real)(&p.v.Price), nil
+	}
+	return nil, lit.ErrIdxBounds
+}
+
+func (p *_xelfProxygenItem) keyIdx(k string) (int, error) {
+	switch k {
+	case "name":
+		return 0, nil
+	case "qty":
+		return 1, nil
+	case "price":
+		return 2, nil
+	}
+	_, i, err := _xelfTypgenItem.ParamByKey(k)
+	return i, err
+}
+
+func (p *_xelfProxygenItem) Idx(i int) (lit.Lit, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return nil, err
+	}
+	switch i {
+	case 0, 1, 2:
+		return el, nil // direct proxies already have the field type
+	}
+	return lit.Convert(el, _xelfTypgenItem.Params[i].Type, 0)
+}
+
+func (p *_xelfProxygenItem) Key(k string) (lit.Lit, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return nil, err
+	}
+	return p.Idx(i)
+}
+
+func (p *_xelfProxygenItem) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	el, err := p.field(i)
+	if err != nil {
+		return p, err
+	}
+	return p, prx.AssignTo(l, el)
+}
+
+func (p *_xelfProxygenItem) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	i, err := p.keyIdx(k)
+	if err != nil {
+		return p, err
+	}
+	_, err = p.SetIdx(i, l)
+	return p, err
+}
+
+func (p *_xelfProxygenItem) IterIdx(it func(int, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i := range _xelfKeysgenItem {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(i, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxygenItem) IterKey(it func(string, lit.Lit) error) error {
+	if p.v == nil {
+		return nil
+	}
+	for i, k := range _xelfKeysgenItem {
+		el, err := p.Idx(i)
+		if err != nil {
+			return err
+		}
+		if err = it(k, el); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfProxygenItem) Assign(l lit.Lit) error {
+	if l == nil || !_xelfTypgenItem.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, _xelfTypgenItem)
+	}
+	if p.v == nil {
+		return prx.ErrNotStruct
+	}
+	b, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || b.IsZero() {
+		*p.v = genItem{}
+		return nil
+	}
+	return b.IterKey(func(k string, e lit.Lit) error {
+		i, err := p.keyIdx(k)
+		if err != nil {
+			return err
+		}
+		el, err := p.field(i)
+		if err != nil {
+			return err
+		}
+		return el.Assign(e)
+	})
+}
+
+type _xelfListgenItem struct {
+	t typ.Type
+	v *[]genItem
+}
+
+func (p *_xelfListgenItem) Typ() typ.Type                { return p.t }
+func (p *_xelfListgenItem) New() lit.Proxy               { return &_xelfListgenItem{p.t, new([]genItem)} }
+func (p *_xelfListgenItem) Ptr() interface{}             { return p.v }
+func (p *_xelfListgenItem) Len() int                     { return len(*p.v) }
+func (p *_xelfListgenItem) IsZero() bool                 { return len(*p.v) == 0 }
+func (p *_xelfListgenItem) String() string               { return bfr.String(p) }
+func (p *_xelfListgenItem) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+func (p *_xelfListgenItem) Element() (lit.Proxy, error)  { return &_xelfProxygenItem{new(genItem)}, nil }
+
+func (p *_xelfListgenItem) WriteBfr(b *bfr.Ctx) error { return prx.WriteList(b, p) }
+
+func (p *_xelfListgenItem) Idx(i int) (lit.Lit, error) {
+	if i < 0 || i >= len(*p.v) {
+		return nil, lit.ErrIdxBounds
+	}
+	return &_xelfProxygenItem{&(*p.v)[i]}, nil
+}
+
+func (p *_xelfListgenItem) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	if i < 0 || i >= len(*p.v) {
+		return p, lit.ErrIdxBounds
+	}
+	return p, prx.AssignTo(l, &_xelfProxygenItem{&(*p.v)[i]})
+}
+
+func (p *_xelfListgenItem) IterIdx(it func(int, lit.Lit) error) error {
+	for i := range *p.v {
+		if err := it(i, &_xelfProxygenItem{&(*p.v)[i]}); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfListgenItem) Append(ls ...lit.Lit) (lit.Appender, error) {
+	v := *p.v
+	for _, l := range ls {
+		var e genItem
+		err := prx.AssignTo(l, &_xelfProxygenItem{&e})
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, e)
+	}
+	return &_xelfListgenItem{p.t, &v}, nil
+}
+
+func (p *_xelfListgenItem) Assign(l lit.Lit) error {
+	if l == nil || !p.t.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, p.t)
+	}
+	b, ok := lit.Deopt(l).(lit.Indexer)
+	if !ok || b.IsZero() {
+		*p.v = nil
+		return nil
+	}
+	v := (*p.v)[:0]
+	err := b.IterIdx(func(i int, l lit.Lit) error {
+		var e genItem
+		err := (&_xelfProxygenItem{&e}).Assign(l)
+		if err != nil {
+			return err
+		}
+		v = append(v, e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*p.v = v
+	return nil
+}
+
+type _xelfDictStr struct {
+	t typ.Type
+	v *map[string]string
+}
+
+func (p *_xelfDictStr) Typ() typ.Type                { return p.t }
+func (p *_xelfDictStr) New() lit.Proxy               { return &_xelfDictStr{p.t, new(map[string]string)} }
+func (p *_xelfDictStr) Ptr() interface{}             { return p.v }
+func (p *_xelfDictStr) Len() int                     { return len(*p.v) }
+func (p *_xelfDictStr) IsZero() bool                 { return len(*p.v) == 0 }
+func (p *_xelfDictStr) String() string               { return bfr.String(p) }
+func (p *_xelfDictStr) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+func (p *_xelfDictStr) Element() (lit.Proxy, error)  { return (*lit.Str)(new(string)), nil }
+
+func (p *_xelfDictStr) WriteBfr(b *bfr.Ctx) error { return prx.WriteDict(b, p) }
+
+func (p *_xelfDictStr) Keys() []string {
+	res := make([]string, 0, len(*p.v))
+	for k := range *p.v {
+		res = append(res, k)
+	}
+	return res
+}
+
+func (p *_xelfDictStr) Key(k string) (lit.Lit, error) {
+	e, ok := (*p.v)[k]
+	if !ok {
+		return lit.Nil, nil
+	}
+	return (*lit.Str)(&e), nil
+}
+
+func (p *_xelfDictStr) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	var e string
+	err := prx.AssignTo(l, (*lit.Str)(&e))
+	if err != nil {
+		return p, err
+	}
+	if *p.v == nil {
+		*p.v = make(map[string]string)
+	}
+	(*p.v)[k] = e
+	return p, nil
+}
+
+func (p *_xelfDictStr) Delete(k string) bool {
+	_, ok := (*p.v)[k]
+	if ok {
+		delete(*p.v, k)
+	}
+	return ok
+}
+
+func (p *_xelfDictStr) IterKey(it func(string, lit.Lit) error) error {
+	for k, e := range *p.v {
+		e := e
+		if err := it(k, (*lit.Str)(&e)); err != nil {
+			if err == lit.BreakIter {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *_xelfDictStr) Assign(l lit.Lit) error {
+	if l == nil || !p.t.Equal(l.Typ()) {
+		return cor.Errorf("%v not assignable to %s", l, p.t)
+	}
+	b, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || b.IsZero() {
+		*p.v = nil
+		return nil
+	}
+	if *p.v == nil {
+		*p.v = make(map[string]string, b.Len())
+	}
+	return b.IterKey(func(k string, l lit.Lit) error {
+		var e string
+		err := ((*lit.Str)(&e)).Assign(l)
+		if err != nil {
+			return err
+		}
+		(*p.v)[k] = e
+		return nil
+	})
+}
@@ -15,6 +15,14 @@ var (
 	ErrUnterminated = cor.StrError("unterminated")
 	// ErrExpectDigit denotes missing digits in a floating point format.
 	ErrExpectDigit = cor.StrError("expect digit")
+	// ErrDepth denotes input exceeding the maximum nesting depth.
+	ErrDepth = cor.StrError("max depth exceeded")
+	// ErrTokens denotes input exceeding the maximum number of tokens.
+	ErrTokens = cor.StrError("max tokens exceeded")
+	// ErrStrLen denotes a string, symbol or number token exceeding the maximum number of bytes.
+	ErrStrLen = cor.StrError("max string bytes exceeded")
+	// ErrLen denotes a container exceeding the maximum number of elements.
+	ErrLen = cor.StrError("max container length exceeded")
 )
 
 // Error is a special lexer error with token information.
@@ -15,13 +15,45 @@ func Read(r io.Reader) (*Tree, error) {
 	return New(r).Tree()
 }
 
+// ReadLimits returns a Tree read from r with resource limits lim or an error.
+func ReadLimits(r io.Reader, lim Limits) (*Tree, error) {
+	l := New(r)
+	l.Limits = lim
+	return l.Tree()
+}
+
+// Limits restricts the resources used to lex and parse untrusted input.
+// Zero or negative values mean no limit.
+type Limits struct {
+	// Depth is the maximum nesting depth of brackets.
+	Depth int
+	// Tokens is the maximum number of tokens. The count starts with the lexer or the last call
+	// to ResetCount, decoders reset it for each value of a stream.
+	Tokens int
+	// Str is the maximum number of bytes of string, symbol and number tokens including quotes.
+	Str int
+	// Len is the maximum number of elements in one container. Each tag counts as one element.
+	Len int
+}
+
+// CheckLen returns an error at token t if n exceeds the maximum container length.
+func (lim Limits) CheckLen(t Token, n int) error {
+	if lim.Len > 0 && n > lim.Len {
+		return ErrorSkip(t, ErrLen, 0, 2)
+	}
+	return nil
+}
+
 // Lexer is simple token lexer.
 type Lexer struct {
-	src      io.RuneScanner
-	cur, nxt rune
-	idx, nxn int
-	err      error
-	lines    []int
+	// Limits are enforced by the lexer and checked by parsers using the lexer.
+	Limits
+	src         io.RuneScanner
+	cur, nxt    rune
+	idx, nxn    int
+	err         error
+	lines       []int
+	ntok, depth int
 }
 
 // New returns a new Lexer for Reader r.
@@ -36,8 +68,31 @@ func New(r io.Reader) *Lexer {
 	return l
 }
 
+// ResetCount resets the token count and nesting depth used to enforce the limits.
+// It is called before reading the next value of a stream to apply the limits per value.
+func (l *Lexer) ResetCount() { l.ntok, l.depth = 0, 0 }
+
 // Token reads and returns the next token or an error.
 func (l *Lexer) Token() (Token, error) {
+	t, err := l.token()
+	if err != nil {
+		return t, err
+	}
+	if l.ntok++; l.Tokens > 0 && l.ntok > l.Tokens {
+		return t, ErrorAt(t, ErrTokens)
+	}
+	switch t.Tok {
+	case '(', '[', '{', '<':
+		if l.depth++; l.Depth > 0 && l.depth > l.Depth {
+			return t, ErrorAt(t, ErrDepth)
+		}
+	case ')', ']', '}', '>':
+		l.depth--
+	}
+	return t, nil
+}
+
+func (l *Lexer) token() (Token, error) {
 	r := l.next()
 	for cor.Space(r) {
 		r = l.next()
@@ -117,6 +172,9 @@ func (l *Lexer) lexString() (Token, error) {
 	for c != EOF && c != q || esc {
 		esc = !esc && c == '\\' && q != '`'
 		b.WriteRune(c)
+		if l.long(&b) {
+			return t, ErrorAt(t, ErrStrLen)
+		}
 		c = l.next()
 	}
 	if c == EOF {
@@ -124,6 +182,9 @@ func (l *Lexer) lexString() (Token, error) {
 		return t, ErrorWant(t, ErrUnterminated, q)
 	}
 	b.WriteRune(q)
+	if l.long(&b) {
+		return t, ErrorAt(t, ErrStrLen)
+	}
 	return l.val(t, b.String())
 }
 
@@ -134,6 +195,9 @@ func (l *Lexer) lexSymbol() (t Token, _ error) {
 	b.WriteRune(l.cur)
 	for cor.NamePart(l.nxt) || cor.Punct(l.nxt) {
 		b.WriteRune(l.next())
+		if l.long(&b) {
+			return t, ErrorAt(t, ErrStrLen)
+		}
 	}
 	return l.val(t, b.String())
 }
@@ -174,6 +238,9 @@ func (l *Lexer) lexNumber() (Token, error) {
 			return t, ErrorAtPos(l.pos(), ErrExpectDigit)
 		}
 	}
+	if l.long(&b) {
+		return t, ErrorAt(t, ErrStrLen)
+	}
 	return l.val(t, b.String())
 }
 
@@ -183,13 +250,18 @@ func (l *Lexer) lexDigits(b *strings.Builder) bool {
 	if !cor.Digit(l.nxt) {
 		return false
 	}
-	for ok := true; ok; ok = cor.Digit(l.nxt) {
+	for ok := true; ok && !l.long(b); ok = cor.Digit(l.nxt) {
 		b.WriteRune(l.nxt)
 		l.next()
 	}
 	return true
 }
 
+// long returns whether the token in b exceeds the maximum number of bytes.
+func (l *Lexer) long(b *strings.Builder) bool {
+	return l.Str > 0 && b.Len() > l.Str
+}
+
 // scanTree returns a token tree constructed from t or an error.
 // If the token is an open paren, trees are scanned until a matching closing paren.
 // Enclosed trees are separated by white-spaces or comma.
@@ -224,6 +296,9 @@ func (l *Lexer) scanTree(t Token) (*Tree, error) {
 		case ':', ';', ',':
 			return res, ErrorAt(t, ErrUnexpected)
 		}
+		if err = l.CheckLen(t, len(res.Seq)+1); err != nil {
+			return res, err
+		}
 		a, err := l.scanTree(t)
 		if err != nil {
 			return a, err
@@ -1,6 +1,7 @@
 package lex
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -58,6 +59,44 @@ func TestLexer(t *testing.T) {
 	}
 }
 
+func TestLimits(t *testing.T) {
+	lim := Limits{Depth: 3, Tokens: 20, Str: 8, Len: 4}
+	tests := []struct {
+		raw string
+		err error
+	}{
+		{"[[[1]] {a:'abcdef'} 12345678]", nil},
+		{"[[[[1]]]]", ErrDepth},
+		{strings.Repeat("[", 1<<16), ErrDepth},
+		{"[[1 2 3] [4 5 6] [7 8 9] [10 11 12]]", ErrTokens},
+		{"'abcdef'", nil},
+		{"'abcdefg'", ErrStrLen},
+		{"'abcdefgh'", ErrStrLen},
+		{"12345678", nil},
+		{"abcdefghi", ErrStrLen},
+		{"123456789", ErrStrLen},
+		{"1.23456789", ErrStrLen},
+		{"[1 2 3 4 5]", ErrLen},
+		{"{a:1 b:2 c:3 d;e;}", ErrLen},
+	}
+	for _, test := range tests {
+		_, err := ReadLimits(strings.NewReader(test.raw), lim)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("scan %s: %v", test.raw, err)
+			}
+			continue
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("%.20s want error %v got %v", test.raw, test.err, err)
+		}
+	}
+	_, err := Read(strings.NewReader(strings.Repeat("[", 100) + strings.Repeat("]", 100)))
+	if err != nil {
+		t.Errorf("want no limits by default got %v", err)
+	}
+}
+
 func src(o, l uint32) Src {
 	return Src{
 		Pos{Off: o, Line: 1, Col: uint16(o)},
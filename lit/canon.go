@@ -0,0 +1,197 @@
+package lit
+
+import (
+	"crypto/sha256"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/typ"
+)
+
+// Canonical returns the canonical JSON representation of l or an error.
+//
+// The format follows the JSON canonicalization scheme of RFC 8785. Keyer literals are written
+// with keys sorted by their UTF-16 code units, numbers use the shortest ECMAScript representation
+// and strings only escape quotes, backslashes and control characters. Unlike RFC 8785 integer
+// values are written exactly, so that large int ids beyond 2^53 keep distinct representations.
+// Records are written as objects, times in UTC, other character literals like span or uuid as
+// their string form and types as strings. The result is the same for generic and proxy literals
+// of equal values.
+func Canonical(l Lit) ([]byte, error) {
+	return appendCanon(nil, l)
+}
+
+// Hash returns the SHA-256 hash of the canonical JSON representation of l or an error.
+// Literals that are equal according to Equal have the same hash.
+func Hash(l Lit) ([sha256.Size]byte, error) {
+	b, err := appendCanon(nil, l)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+func appendCanon(b []byte, l Lit) (_ []byte, err error) {
+	if l == nil {
+		return append(b, "null"...), nil
+	}
+	if o, ok := l.(Opter); ok {
+		if l = o.Some(); l == nil {
+			return append(b, "null"...), nil
+		}
+	}
+	switch v := l.(type) {
+	case typ.Type:
+		return appendCanonStr(b, v.String()), nil
+	case Null:
+		return append(b, "null"...), nil
+	case Indexer:
+		if r, ok := v.(Keyer); ok {
+			if k := r.Typ().Kind & typ.MaskElem; k == typ.KindRec || k == typ.KindObj {
+				return appendCanonKeyer(b, r)
+			}
+		}
+		b = append(b, '[')
+		err = v.IterIdx(func(i int, el Lit) error {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b, err = appendCanon(b, el)
+			return err
+		})
+		return append(b, ']'), err
+	case Keyer:
+		return appendCanonKeyer(b, v)
+	case valer:
+		switch w := v.Val().(type) {
+		case nil:
+			return append(b, "null"...), nil
+		case bool:
+			return strconv.AppendBool(b, w), nil
+		case int64:
+			return strconv.AppendInt(b, w, 10), nil
+		case float64:
+			return appendCanonNum(b, w)
+		case time.Time:
+			return appendCanonStr(b, Time(w.UTC()).Char()), nil
+		}
+		if c, ok := v.(Character); ok {
+			return appendCanonStr(b, c.Char()), nil
+		}
+	}
+	return nil, cor.Errorf("cannot canonicalize %T", l)
+}
+
+func appendCanonKeyer(b []byte, v Keyer) (_ []byte, err error) {
+	keys := v.Keys()
+	sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+	b = append(b, '{')
+	for i, k := range keys {
+		el, err := v.Key(k)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendCanonStr(b, k)
+		b = append(b, ':')
+		b, err = appendCanon(b, el)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// appendCanonNum appends f in the ECMAScript number format required by RFC 8785.
+func appendCanonNum(b []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, cor.Errorf("cannot canonicalize number %v", f)
+	}
+	if f == 0 {
+		return append(b, '0'), nil
+	}
+	if a := math.Abs(f); a >= 1e-6 && a < 1e21 {
+		return strconv.AppendFloat(b, f, 'f', -1, 64), nil
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	// go pads the exponent to two digits and ecmascript does not
+	if i := strings.IndexByte(s, 'e'); i > 0 && len(s) > i+3 && s[i+2] == '0' {
+		s = s[:i+2] + s[i+3:]
+	}
+	return append(b, s...), nil
+}
+
+// appendCanonStr appends s as quoted JSON string with minimal escaping as required by RFC 8785.
+func appendCanonStr(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && n == 1 {
+				b = append(b, "\ufffd"...)
+			} else {
+				b = append(b, s[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			b = append(b, '\\', c)
+		case '\b':
+			b = append(b, '\\', 'b')
+		case '\f':
+			b = append(b, '\\', 'f')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if c < 0x20 {
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				b = append(b, c)
+			}
+		}
+		i++
+	}
+	return append(b, '"')
+}
+
+// lessUTF16 returns whether a sorts before b when compared by UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			ua, ub := utf16Unit(ra), utf16Unit(rb)
+			if ua != ub {
+				return ua < ub
+			}
+			return ra < rb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// utf16Unit returns the first UTF-16 code unit of r.
+func utf16Unit(r rune) rune {
+	if r >= 0x10000 {
+		r1, _ := utf16.EncodeRune(r)
+		return r1
+	}
+	return r
+}
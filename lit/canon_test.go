@@ -0,0 +1,86 @@
+package lit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		raw  string
+		typ  typ.Type
+		want string
+	}{
+		{`null`, typ.Void, `null`},
+		{`[1 2.5 1e21 0.0000001 -0]`, typ.Void, `[1,2.5,1e+21,1e-7,0]`},
+		{`[1 2]`, typ.List(typ.Int), `[1,2]`},
+		{`[9007199254740993 -9223372036854775808]`, typ.List(typ.Int),
+			`[9007199254740993,-9223372036854775808]`},
+		{`[9007199254740993 1e20]`, typ.Void, `[9007199254740993,100000000000000000000]`},
+		{`{b:1 a:'x\ny' '\ufb33':4 '\ud83d\ude00':3 '\u20ac':2}`, typ.Void,
+			"{\"a\":\"x\\ny\",\"b\":1,\"\u20ac\":2,\"\U0001F600\":3,\"\ufb33\":4}"},
+		{`{b:true a:null}`, typ.Void, `{"a":null,"b":true}`},
+		{`{id:1 name:'</a>'}`, typ.Rec([]typ.Param{
+			{Name: "name", Type: typ.Str},
+			{Name: "id", Type: typ.Int},
+		}), `{"id":1,"name":"</a>"}`},
+		{`'2019-01-17'`, typ.Time, `"2019-01-17T00:00:00Z"`},
+		{`'2019-01-17T01:30:00+01:00'`, typ.Time, `"2019-01-17T00:30:00Z"`},
+	}
+	for _, test := range tests {
+		l, err := Read(strings.NewReader(test.raw))
+		if err == nil && test.typ != typ.Void {
+			l, err = Convert(l, test.typ, 0)
+		}
+		if err != nil {
+			t.Errorf("read %s err: %v", test.raw, err)
+			continue
+		}
+		b, err := Canonical(l)
+		if err != nil {
+			t.Errorf("canonical %s err: %v", test.raw, err)
+			continue
+		}
+		if got := string(b); got != test.want {
+			t.Errorf("canonical %s want %s got %s", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, _ := Read(strings.NewReader(`{a:1 b:[1 2] c:'x'}`))
+	b, _ := Read(strings.NewReader(`{c:'x' b:[1,2] a:1.0}`))
+	c, _ := Read(strings.NewReader(`{a:1 b:[2 1] c:'x'}`))
+	ha, err := Hash(a)
+	if err != nil {
+		t.Fatalf("hash err: %v", err)
+	}
+	hb, _ := Hash(b)
+	hc, _ := Hash(c)
+	if ha != hb {
+		t.Errorf("want equal hash for %s and %s", a, b)
+	}
+	if ha == hc {
+		t.Errorf("want different hash for %s and %s", a, c)
+	}
+	for _, pair := range [][2]Lit{
+		{Int(9007199254740993), Int(9007199254740992)},
+		{numeral("9007199254740993"), Num(9007199254740992)},
+	} {
+		ha, _ := Hash(pair[0])
+		hb, _ := Hash(pair[1])
+		if ha == hb {
+			t.Errorf("want different hash for %s and %s", pair[0], pair[1])
+		}
+	}
+	utc := time.Date(2019, 1, 17, 0, 30, 0, 5, time.UTC)
+	loc := utc.In(time.FixedZone("", 3600))
+	hu, _ := Hash(Time(utc))
+	hl, _ := Hash(Time(loc))
+	if !Equal(Time(utc), Time(loc)) || hu != hl {
+		t.Errorf("want equal hash for the same instant in different zones")
+	}
+}
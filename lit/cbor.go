@@ -0,0 +1,642 @@
+package lit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/typ"
+)
+
+// CBOR major types and tags as specified in RFC 8949.
+const (
+	cborUint  = 0 << 5
+	cborNint  = 1 << 5
+	cborBytes = 2 << 5
+	cborText  = 3 << 5
+	cborArray = 4 << 5
+	cborMap   = 5 << 5
+	cborTag   = 6 << 5
+	cborPrim  = 7 << 5
+
+	cborFalse = cborPrim | 20
+	cborTrue  = cborPrim | 21
+	cborNull  = cborPrim | 22
+	cborUndef = cborPrim | 23
+	cborHalf  = cborPrim | 25
+	cborFloat = cborPrim | 26
+	cborDbl   = cborPrim | 27
+	cborBreak = cborPrim | 31
+
+	// cborTagTime is the standard tag for RFC 3339 date time text.
+	cborTagTime = 0
+	// cborTagEpoch is the standard tag for epoch based date time values.
+	cborTagEpoch = 1
+	// cborTagUUID is the registered tag for binary uuids.
+	cborTagUUID = 37
+	// cborTagSpan is the registered tag for durations in seconds.
+	cborTagSpan = 1002
+)
+
+var ErrCBOR = cor.StrError("invalid cbor")
+
+// MarshalCBOR returns the CBOR encoding of l or an error.
+//
+// Numbers are written as integers if possible or as double floats. Char, str and enum literals are
+// written as text, raw as byte string, uuid with tag 37 and span in seconds with tag 1002. Times
+// in whole UTC seconds use the epoch tag 1, all other times RFC 3339 text with nanoseconds and
+// zone offset with tag 0. Idxer literals are written as arrays and all keyers, including records,
+// as maps with text keys. Types are written as text.
+func MarshalCBOR(l Lit) ([]byte, error) {
+	return appendCBOR(nil, l)
+}
+
+// WriteCBOR writes the CBOR encoding of l to w or returns an error.
+func WriteCBOR(w io.Writer, l Lit) error {
+	b, err := appendCBOR(nil, l)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func appendCBOR(b []byte, l Lit) (_ []byte, err error) {
+	if l == nil {
+		return append(b, cborNull), nil
+	}
+	if o, ok := l.(Opter); ok {
+		if l = o.Some(); l == nil {
+			return append(b, cborNull), nil
+		}
+	}
+	switch v := l.(type) {
+	case typ.Type:
+		return appendCBORText(b, v.String()), nil
+	case Null:
+		return append(b, cborNull), nil
+	case Indexer:
+		if r, ok := v.(Keyer); ok {
+			if k := r.Typ().Kind & typ.MaskElem; k == typ.KindRec || k == typ.KindObj {
+				return appendCBORKeyer(b, r)
+			}
+		}
+		b = appendCBORHead(b, cborArray, uint64(v.Len()))
+		err = v.IterIdx(func(_ int, el Lit) error {
+			b, err = appendCBOR(b, el)
+			return err
+		})
+		return b, err
+	case Keyer:
+		return appendCBORKeyer(b, v)
+	case Numeral:
+		return appendCBORNumeral(b, v), nil
+	case valer:
+		switch w := v.Val().(type) {
+		case nil:
+			return append(b, cborNull), nil
+		case bool:
+			if w {
+				return append(b, cborTrue), nil
+			}
+			return append(b, cborFalse), nil
+		case int64:
+			return appendCBORInt(b, w), nil
+		case float64:
+			return appendCBORNum(b, w), nil
+		case string:
+			return appendCBORText(b, w), nil
+		case []byte:
+			b = appendCBORHead(b, cborBytes, uint64(len(w)))
+			return append(b, w...), nil
+		case [16]byte:
+			b = appendCBORHead(b, cborTag, cborTagUUID)
+			b = appendCBORHead(b, cborBytes, 16)
+			return append(b, w[:]...), nil
+		case time.Time:
+			if _, off := w.Zone(); off == 0 && w.Nanosecond() == 0 {
+				b = appendCBORHead(b, cborTag, cborTagEpoch)
+				return appendCBORInt(b, w.Unix()), nil
+			}
+			b = appendCBORHead(b, cborTag, cborTagTime)
+			return appendCBORText(b, w.Format(time.RFC3339Nano)), nil
+		case time.Duration:
+			b = appendCBORHead(b, cborTag, cborTagSpan)
+			if w%time.Second == 0 {
+				return appendCBORInt(b, int64(w/time.Second)), nil
+			}
+			return appendCBORDbl(b, w.Seconds()), nil
+		}
+	}
+	return nil, cor.Errorf("cbor cannot encode %s", l.Typ())
+}
+
+func appendCBORKeyer(b []byte, v Keyer) (_ []byte, err error) {
+	b = appendCBORHead(b, cborMap, uint64(v.Len()))
+	err = v.IterKey(func(k string, el Lit) error {
+		b = appendCBORText(b, k)
+		b, err = appendCBOR(b, el)
+		return err
+	})
+	return b, err
+}
+
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, major|25, 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(n))
+	case n <= math.MaxUint32:
+		b = append(b, major|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], uint32(n))
+	default:
+		b = append(b, major|27, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], n)
+	}
+	return b
+}
+
+func appendCBORInt(b []byte, n int64) []byte {
+	if n < 0 {
+		return appendCBORHead(b, cborNint, uint64(-1-n))
+	}
+	return appendCBORHead(b, cborUint, uint64(n))
+}
+
+// appendCBORNumeral appends numerals in the CBOR integer range as integers and others as double.
+func appendCBORNumeral(b []byte, v Numeral) []byte {
+	if n, err := v.Int(); err == nil {
+		return appendCBORInt(b, n)
+	}
+	if n, ok := new(big.Int).SetString(v.Raw, 10); ok {
+		if n.Sign() >= 0 && n.IsUint64() {
+			return appendCBORHead(b, cborUint, n.Uint64())
+		}
+		if n.Sign() < 0 {
+			if n.Not(n); n.IsUint64() {
+				return appendCBORHead(b, cborNint, n.Uint64())
+			}
+		}
+	}
+	return appendCBORDbl(b, v.Num())
+}
+
+func appendCBORNum(b []byte, f float64) []byte {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return appendCBORInt(b, int64(f))
+	}
+	return appendCBORDbl(b, f)
+}
+
+func appendCBORDbl(b []byte, f float64) []byte {
+	b = append(b, cborDbl, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(b[len(b)-8:], math.Float64bits(f))
+	return b
+}
+
+func appendCBORText(b []byte, s string) []byte {
+	b = appendCBORHead(b, cborText, uint64(len(s)))
+	return append(b, s...)
+}
+
+// UnmarshalCBOR decodes b and returns a literal converted to type t or an error.
+func UnmarshalCBOR(b []byte, t typ.Type) (Lit, error) {
+	return ReadCBOR(bytes.NewReader(b), t)
+}
+
+// ReadCBOR reads one CBOR data item from r and returns a literal converted to type t or an error.
+// A void or any type returns generic literals. Integers are read as int or as numeral if they
+// overflow int64, floats as num, text as char, byte strings as raw, arrays as list and maps as
+// dict literals.
+func ReadCBOR(r io.Reader, t typ.Type) (Lit, error) {
+	d := &cborDecoder{ByteReader: cborReader(r)}
+	l, err := d.read()
+	if err != nil {
+		return nil, err
+	}
+	return cborConvert(l, t)
+}
+
+// AssignCBOR reads one CBOR data item from r and assigns it to the proxy p or returns an error.
+// Like the Decoder it assigns arrays to appenders and records, and maps to dictionaries and
+// records element by element. Other data items are read as generic literal and then converted
+// and assigned.
+func AssignCBOR(r io.Reader, p Proxy) error {
+	d := &cborDecoder{ByteReader: cborReader(r)}
+	c, err := d.ReadByte()
+	if err != nil {
+		return err
+	}
+	return d.assign(c, p)
+}
+
+func cborReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return bufio.NewReader(r)
+}
+
+func cborConvert(l Lit, t typ.Type) (Lit, error) {
+	if t == typ.Void || t == typ.Any {
+		return l, nil
+	}
+	if l == Nil {
+		return Null(t), nil
+	}
+	return Convert(l, t, 0)
+}
+
+// cborMaxDepth is the maximum nesting depth of arrays, maps and tags.
+const cborMaxDepth = 1000
+
+type cborDecoder struct {
+	io.ByteReader
+	depth int
+}
+
+func (d *cborDecoder) read() (Lit, error) {
+	c, err := d.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.item(c)
+}
+
+// assign assigns the data item starting with the initial byte c to the proxy p.
+func (d *cborDecoder) assign(c byte, p Proxy) error {
+	if s, ok := p.(SomeProxy); ok && c != cborNull && c != cborUndef {
+		p = s.Proxy
+	}
+	switch c & 0xe0 {
+	case cborArray:
+		switch v := p.(type) {
+		case Record:
+			return d.each(c, func(i int, b byte) error {
+				return d.assignField(b, v, i, "")
+			})
+		case Appender:
+			return d.assignList(c, v, p)
+		}
+	case cborMap:
+		switch v := p.(type) {
+		case Record:
+			return d.eachKey(c, func(k string, b byte) error {
+				return d.assignField(b, v, -1, k)
+			})
+		case Dictionary:
+			return d.eachKey(c, func(k string, b byte) error {
+				el, err := v.Element()
+				if err != nil {
+					return err
+				}
+				err = d.assign(b, el)
+				if err != nil {
+					return err
+				}
+				_, err = v.SetKey(k, decoded(el))
+				return err
+			})
+		}
+	}
+	l, err := d.item(c)
+	if err != nil {
+		return err
+	}
+	// numerals are assigned as is, so that proxies of unsigned go types can hold them
+	if _, ok := l.(Numeral); !ok {
+		l, err = cborConvert(l, p.Typ())
+		if err != nil {
+			return err
+		}
+	}
+	return p.Assign(l)
+}
+
+func (d *cborDecoder) assignList(c byte, v Appender, p Proxy) error {
+	err := d.each(c, func(_ int, b byte) error {
+		el, err := v.Element()
+		if err != nil {
+			return err
+		}
+		err = d.assign(b, el)
+		if err != nil || appendPtr(v, el) {
+			return err
+		}
+		v, err = v.Append(decoded(el))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if Lit(v) != Lit(p) {
+		return p.Assign(v)
+	}
+	return nil
+}
+
+// assignField assigns the data item starting with b to a record field either by idx or key.
+func (d *cborDecoder) assignField(b byte, v Record, idx int, key string) (err error) {
+	var el Lit
+	var f *typ.Param
+	if key != "" {
+		el, err = v.Key(key)
+		if err == nil {
+			f, _, err = v.Typ().ParamByKey(key)
+		}
+	} else {
+		el, err = v.Idx(idx)
+		if err == nil {
+			f, err = v.Typ().ParamByIdx(idx)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if p, ok := el.(Proxy); ok {
+		return d.assign(b, p)
+	}
+	p := ZeroProxy(f.Type)
+	err = d.assign(b, p)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		_, err = v.SetKey(key, decoded(p))
+	} else {
+		_, err = v.SetIdx(idx, decoded(p))
+	}
+	return err
+}
+
+// each calls fn with the index and initial byte of each element in the array starting with c.
+func (d *cborDecoder) each(c byte, fn func(int, byte) error) error {
+	ai := c & 0x1f
+	var n uint64
+	if ai != 31 {
+		var err error
+		n, err = readCBORArg(d, ai)
+		if err != nil {
+			return err
+		}
+	}
+	if d.depth++; d.depth > cborMaxDepth {
+		return cor.Errorf("cbor nesting exceeds %d: %w", cborMaxDepth, ErrCBOR)
+	}
+	defer func() { d.depth-- }()
+	for i := 0; ai == 31 || uint64(i) < n; i++ {
+		b, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+		if ai == 31 && b == cborBreak {
+			return nil
+		}
+		err = fn(i, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachKey calls fn with the text key and the initial value byte of each entry in the map
+// starting with c. The value must be read by fn.
+func (d *cborDecoder) eachKey(c byte, fn func(string, byte) error) error {
+	return d.each(c, func(_ int, b byte) error {
+		k, err := d.item(b)
+		if err != nil {
+			return err
+		}
+		key, ok := k.(Char)
+		if !ok {
+			return cor.Errorf("want text map key got %s: %w", k, ErrCBOR)
+		}
+		b, err = d.ReadByte()
+		if err != nil {
+			return err
+		}
+		return fn(string(key), b)
+	})
+}
+
+// item returns the data item starting with the initial byte c or an error.
+func (d *cborDecoder) item(c byte) (Lit, error) {
+	major, ai := c&0xe0, c&0x1f
+	switch major {
+	case cborPrim:
+		switch c {
+		case cborFalse:
+			return False, nil
+		case cborTrue:
+			return True, nil
+		case cborNull, cborUndef:
+			return Nil, nil
+		case cborHalf:
+			n, err := readCBORUint(d, 2)
+			return Num(halfFloat(uint16(n))), err
+		case cborFloat:
+			n, err := readCBORUint(d, 4)
+			return Num(math.Float32frombits(uint32(n))), err
+		case cborDbl:
+			n, err := readCBORUint(d, 8)
+			return Num(math.Float64frombits(n)), err
+		case cborBreak:
+			return nil, cor.Errorf("unexpected break: %w", ErrCBOR)
+		}
+		return nil, cor.Errorf("unsupported cbor simple value %d: %w", ai, ErrCBOR)
+	case cborArray:
+		res := &List{}
+		err := d.each(c, func(_ int, b byte) error {
+			el, err := d.item(b)
+			res.Data = append(res.Data, el)
+			return err
+		})
+		return res, err
+	case cborMap:
+		res := &Dict{}
+		err := d.eachKey(c, func(k string, b byte) error {
+			el, err := d.item(b)
+			res.List = append(res.List, Keyed{k, el})
+			return err
+		})
+		return res, err
+	}
+	if ai == 31 {
+		return d.chunks(major)
+	}
+	n, err := readCBORArg(d, ai)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		if n > math.MaxInt64 {
+			return makeNumeral(strconv.FormatUint(n, 10), float64(n)), nil
+		}
+		return Int(n), nil
+	case cborNint:
+		if n > math.MaxInt64 {
+			raw := new(big.Int).Add(new(big.Int).SetUint64(n), big.NewInt(1))
+			return makeNumeral("-"+raw.String(), -1-float64(n)), nil
+		}
+		return Int(-1 - int64(n)), nil
+	case cborBytes:
+		b, err := readCBORBytes(d, n)
+		return Raw(b), err
+	case cborText:
+		b, err := readCBORBytes(d, n)
+		return Char(b), err
+	}
+	if d.depth++; d.depth > cborMaxDepth {
+		return nil, cor.Errorf("cbor nesting exceeds %d: %w", cborMaxDepth, ErrCBOR)
+	}
+	defer func() { d.depth-- }()
+	el, err := d.read()
+	if err != nil {
+		return nil, err
+	}
+	return cborTagged(n, el)
+}
+
+// chunks returns the indefinite length byte or text string of the major type.
+func (d *cborDecoder) chunks(major byte) (Lit, error) {
+	if major != cborBytes && major != cborText {
+		return nil, cor.Errorf("unexpected indefinite length: %w", ErrCBOR)
+	}
+	var res []byte
+	for {
+		c, err := d.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == cborBreak {
+			break
+		}
+		if c&0xe0 != major || c&0x1f == 31 {
+			return nil, cor.Errorf("want definite string chunk: %w", ErrCBOR)
+		}
+		n, err := readCBORArg(d, c&0x1f)
+		if err != nil {
+			return nil, err
+		}
+		b, err := readCBORBytes(d, n)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b...)
+	}
+	if major == cborText {
+		return Char(res), nil
+	}
+	if res == nil {
+		res = []byte{}
+	}
+	return Raw(res), nil
+}
+
+func cborTagged(tag uint64, el Lit) (Lit, error) {
+	switch tag {
+	case cborTagTime:
+		if c, ok := el.(Character); ok {
+			t, err := time.Parse(time.RFC3339Nano, c.Char())
+			if err == nil {
+				return Time(t), nil
+			}
+		}
+	case cborTagEpoch:
+		if n, ok := el.(Int); ok {
+			return Time(time.Unix(int64(n), 0).UTC()), nil
+		}
+		if n, ok := el.(Numeric); ok {
+			sec, frac := math.Modf(n.Num())
+			return Time(time.Unix(int64(sec), int64(frac*1e9)).UTC()), nil
+		}
+	case cborTagUUID:
+		if b, ok := el.(Raw); ok && len(b) == 16 {
+			var u UUID
+			copy(u[:], b)
+			return u, nil
+		}
+	case cborTagSpan:
+		if n, ok := el.(Numeric); ok {
+			return Span(time.Duration(n.Num() * float64(time.Second))), nil
+		}
+	default:
+		// ignore unknown tags and return the tagged item
+		return el, nil
+	}
+	return nil, cor.Errorf("invalid cbor tag %d content %s: %w", tag, el, ErrCBOR)
+}
+
+func readCBORArg(r io.ByteReader, ai byte) (uint64, error) {
+	switch {
+	case ai < 24:
+		return uint64(ai), nil
+	case ai < 28:
+		return readCBORUint(r, 1<<(ai-24))
+	}
+	return 0, cor.Errorf("reserved additional info %d: %w", ai, ErrCBOR)
+}
+
+func readCBORUint(r io.ByteReader, n int) (res uint64, _ error) {
+	for i := 0; i < n; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		res = res<<8 | uint64(c)
+	}
+	return res, nil
+}
+
+func readCBORBytes(r io.ByteReader, n uint64) ([]byte, error) {
+	res := make([]byte, 0, capHint(n))
+	for i := uint64(0); i < n; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// capHint limits the preallocated capacity for untrusted length arguments.
+func capHint(n uint64) int {
+	if n > 1024 {
+		return 1024
+	}
+	return int(n)
+}
+
+func halfFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
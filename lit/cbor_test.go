@@ -0,0 +1,125 @@
+package lit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestCBOR(t *testing.T) {
+	tests := []struct {
+		Lit
+		hex string
+	}{
+		{Nil, "f6"},
+		{True, "f5"},
+		{Int(0), "00"},
+		{Int(23), "17"},
+		{Int(1000), "1903e8"},
+		{Int(-1000), "3903e7"},
+		{Int(1<<53 + 1), "1b0020000000000001"},
+		{Int(math.MinInt64), "3b7fffffffffffffff"},
+		{numeral("18446744073709551615"), "1bffffffffffffffff"},
+		{numeral("-18446744073709551616"), "3bffffffffffffffff"},
+		{Num(1.1), "fb3ff199999999999a"},
+		{Char("IETF"), "6449455446"},
+		{Raw("\x01\x02"), "420102"},
+		{&List{Data: []Lit{Int(1), Int(2)}}, "820102"},
+		{&Dict{List: []Keyed{{"a", Int(1)}}}, "a1616101"},
+		{Time(time.Unix(1363896240, 0).UTC()), "c11a514b67b0"},
+		{Span(90 * time.Second), "d903ea185a"},
+	}
+	for _, test := range tests {
+		b, err := MarshalCBOR(test.Lit)
+		if err != nil {
+			t.Errorf("marshal %s error: %v", test.Lit, err)
+			continue
+		}
+		if got := hex.EncodeToString(b); got != test.hex {
+			t.Errorf("marshal %s want %s got %s", test.Lit, test.hex, got)
+		}
+		l, err := UnmarshalCBOR(b, test.Typ())
+		if err != nil {
+			t.Errorf("unmarshal %s error: %v", test.hex, err)
+			continue
+		}
+		if !Equal(l, test.Lit) && (l != Nil || test.Lit != Nil) {
+			t.Errorf("unmarshal %s want %s got %s", test.hex, test.Lit, l)
+		}
+	}
+	for _, tt := range []time.Time{
+		time.Date(2020, 2, 2, 12, 0, 0, 123456789, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 1, 1, time.UTC),
+		time.Date(1200, 6, 15, 8, 30, 0, 0, time.FixedZone("", 2*3600)),
+	} {
+		b, err := MarshalCBOR(Time(tt))
+		if err != nil {
+			t.Errorf("marshal time %s err: %v", tt, err)
+			continue
+		}
+		l, err := UnmarshalCBOR(b, typ.Time)
+		if err != nil {
+			t.Errorf("unmarshal time %s err: %v", tt, err)
+			continue
+		}
+		if !Equal(l, Time(tt)) || l.String() != Time(tt).String() {
+			t.Errorf("time roundtrip want %s got %s", Time(tt), l)
+		}
+	}
+	// indefinite length text and array
+	l, err := UnmarshalCBOR([]byte{0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff}, typ.Str)
+	if err != nil || l != Str("abc") {
+		t.Errorf("unmarshal indefinite text got %s %v", l, err)
+	}
+	l, err = UnmarshalCBOR([]byte{0x9f, 0x01, 0xf9, 0x3c, 0x00, 0xff}, typ.List(typ.Int))
+	if err != nil || l.String() != "[1 1]" {
+		t.Errorf("unmarshal indefinite list got %s %v", l, err)
+	}
+	deep := bytes.Repeat([]byte{0x81}, cborMaxDepth+1)
+	_, err = UnmarshalCBOR(append(deep, 0x01), typ.Void)
+	if !errors.Is(err, ErrCBOR) {
+		t.Errorf("unmarshal nested arrays want depth error got %v", err)
+	}
+}
+
+func TestCBORTyped(t *testing.T) {
+	rt, err := typ.Read(strings.NewReader(
+		`<rec id:uuid name:str data:raw at:time dur:span n:int r:real tags:list|str note?:str>`))
+	if err != nil {
+		t.Fatalf("read type: %v", err)
+	}
+	l, err := Read(strings.NewReader(`{id:'6ba7b810-9dad-11d1-80b4-00c04fd430c8' name:'x'
+		data:'AQI=' at:'2019-01-17T10:20:30.5Z' dur:'1:30' n:-7 r:2.5 tags:['a' 'b']}`))
+	if err != nil {
+		t.Fatalf("read lit: %v", err)
+	}
+	want, err := Convert(l, rt, 0)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	var buf bytes.Buffer
+	err = WriteCBOR(&buf, want)
+	if err != nil {
+		t.Fatalf("write cbor: %v", err)
+	}
+	if jsn, _ := want.MarshalJSON(); buf.Len() >= len(jsn) {
+		t.Errorf("want cbor smaller than json got %d >= %d", buf.Len(), len(jsn))
+	}
+	p, err := MakeRec(rt)
+	if err != nil {
+		t.Fatalf("make rec: %v", err)
+	}
+	err = AssignCBOR(&buf, p)
+	if err != nil {
+		t.Fatalf("assign cbor: %v", err)
+	}
+	if !Equal(p, want) {
+		t.Errorf("want %s got %s", want, p)
+	}
+}
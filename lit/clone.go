@@ -0,0 +1,167 @@
+package lit
+
+import (
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/typ"
+)
+
+// Clone returns a deep copy of l that does not share any mutable data with l or an error.
+// Containers are copied into generic list, dict and record literals that keep the type of l,
+// optional and any wrappers are preserved. Other proxies are copied using New and Assign.
+// Sequences are forced and cloned as lists.
+// Use CloneProxy to copy proxies and keep the underlying go type.
+func Clone(l Lit) (Lit, error) {
+	switch v := l.(type) {
+	case nil:
+		return nil, nil
+	case Some:
+		c, err := Clone(v.Lit)
+		if err != nil {
+			return nil, err
+		}
+		return Some{c}, nil
+	case Any:
+		c, err := Clone(v.Lit)
+		if err != nil {
+			return nil, err
+		}
+		return Any{c}, nil
+	case SomeProxy:
+		c, err := Clone(v.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := c.(Proxy); ok {
+			return SomeProxy{p}, nil
+		}
+		return Some{c}, nil
+	case Raw:
+		if v == nil {
+			return v, nil
+		}
+		return append(Raw{}, v...), nil
+	case *Raw:
+		res := Raw(nil)
+		if *v != nil {
+			res = append(Raw{}, *v...)
+		}
+		return &res, nil
+	case *Seq:
+		// sequences share their iterator, so we need to force them into a list
+		res, err := v.List()
+		if err != nil {
+			return nil, err
+		}
+		return Clone(res)
+	case *List:
+		res := &List{Elem: v.Elem, Data: make([]Lit, 0, len(v.Data))}
+		for _, el := range v.Data {
+			c, err := Clone(el)
+			if err != nil {
+				return nil, err
+			}
+			res.Data = append(res.Data, c)
+		}
+		return res, nil
+	case *Dict:
+		res := &Dict{Elem: v.Elem, List: make([]Keyed, 0, len(v.List))}
+		for _, kv := range v.List {
+			c, err := Clone(kv.Lit)
+			if err != nil {
+				return nil, err
+			}
+			res.List = append(res.List, Keyed{kv.Key, c})
+		}
+		return res, nil
+	case Record:
+		res, err := MakeRec(v.Typ())
+		if err != nil {
+			return nil, err
+		}
+		err = v.IterKey(func(k string, el Lit) error {
+			c, err := Clone(el)
+			if err != nil {
+				return err
+			}
+			_, err = res.SetKey(k, c)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case Keyer:
+		res := &Dict{List: make([]Keyed, 0, v.Len())}
+		if t := v.Typ(); t.Kind&typ.MaskElem == typ.KindDict {
+			res.Elem = t.Elem()
+		}
+		err := v.IterKey(func(k string, el Lit) error {
+			c, err := Clone(el)
+			if err != nil {
+				return err
+			}
+			res.List = append(res.List, Keyed{k, c})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case Indexer:
+		res := &List{Data: make([]Lit, 0, v.Len())}
+		if t := v.Typ(); t.Kind&typ.MaskElem == typ.KindList {
+			res.Elem = t.Elem()
+		}
+		err := v.IterIdx(func(_ int, el Lit) error {
+			c, err := Clone(el)
+			if err != nil {
+				return err
+			}
+			res.Data = append(res.Data, c)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case Proxy:
+		res := v.New()
+		err := res.Assign(v)
+		if err != nil {
+			return nil, cor.Errorf("clone %s: %w", v.Typ(), err)
+		}
+		return res, nil
+	}
+	// all other literals are immutable values
+	return l, nil
+}
+
+// CloneProxy returns a deep copy of proxy p with the same go type or an error.
+// It creates a new proxy with New and assigns a clone of p. Generic containers are cloned
+// and optional proxies are preserved.
+func CloneProxy(p Proxy) (Proxy, error) {
+	switch v := p.(type) {
+	case SomeProxy:
+		c, err := CloneProxy(v.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		return SomeProxy{c}, nil
+	case *List, *Dict, *Rec, *Raw:
+		c, err := Clone(p)
+		if err != nil {
+			return nil, err
+		}
+		return c.(Proxy), nil
+	}
+	c, err := Clone(p)
+	if err != nil {
+		return nil, err
+	}
+	res := p.New()
+	err = res.Assign(c)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
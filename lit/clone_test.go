@@ -0,0 +1,65 @@
+package lit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/typ"
+)
+
+// failStr is a str proxy that cannot be assigned.
+type failStr struct{ *Str }
+
+func (p failStr) New() Proxy         { return failStr{new(Str)} }
+func (p failStr) Assign(l Lit) error { return ErrUnconv }
+
+func TestClone(t *testing.T) {
+	l, err := Read(strings.NewReader(`{a:[1 2 {b:'x'}] c:{d:true}}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	raw := Raw("abc")
+	str := Str("s")
+	orig := &List{Elem: typ.Any, Data: []Lit{l, raw, &str, Some{&List{Elem: typ.Int}}}}
+	want := orig.String()
+	c, err := Clone(orig)
+	if err != nil {
+		t.Fatalf("clone err: %v", err)
+	}
+	if got := c.String(); got != want {
+		t.Fatalf("want clone %s got %s", want, got)
+	}
+	if !c.Typ().Equal(orig.Typ()) {
+		t.Errorf("want clone type %s got %s", orig.Typ(), c.Typ())
+	}
+	cl := c.(*List)
+	_, err = SetPath(cl, Path{{Idx: 0}, {Key: "a"}, {Idx: 2}, {Key: "b"}}, Str("y"), false)
+	if err != nil {
+		t.Fatalf("set path err: %v", err)
+	}
+	cl.Data[1].(Raw)[0] = 'z'
+	*cl.Data[2].(*Str) = "t"
+	if _, ok := cl.Data[3].(Some); !ok {
+		t.Errorf("want some wrapper preserved got %T", cl.Data[3])
+	}
+	if got := orig.String(); got != want {
+		t.Errorf("want original unchanged %s got %s", want, got)
+	}
+	if cl.Data[2] == orig.Data[2] {
+		t.Errorf("want new proxy for str pointer")
+	}
+	p, err := CloneProxy(SomeProxy{&str})
+	if err != nil {
+		t.Fatalf("clone proxy err: %v", err)
+	}
+	sp, ok := p.(SomeProxy)
+	if !ok || sp.Proxy == Proxy(&str) || sp.String() != `'s'` {
+		t.Errorf("want cloned some proxy got %T %s", p, p)
+	}
+	s := Str("x")
+	_, err = Clone(&List{Data: []Lit{Str("a"), failStr{&s}}})
+	if !errors.Is(err, ErrUnconv) {
+		t.Errorf("want clone error got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package lit
 
 import (
 	"bytes"
+	"math"
 	"time"
 
 	"github.com/mb0/xelf/cor"
@@ -16,6 +17,11 @@ func Equal(a, b Lit) bool {
 	if !a.Typ().Equal(b.Typ()) {
 		return false
 	}
+	a, aok := forceSeq(a)
+	b, bok := forceSeq(b)
+	if !aok || !bok {
+		return false
+	}
 	switch v := a.(type) {
 	case typ.Type:
 		w, ok := b.(typ.Type)
@@ -36,11 +42,23 @@ func Equal(a, b Lit) bool {
 	return false
 }
 
+// forceSeq returns l or the list of elements if l is a sequence and whether that succeeded.
+func forceSeq(l Lit) (Lit, bool) {
+	if s, ok := l.(*Seq); ok {
+		res, err := s.List()
+		return res, err == nil
+	}
+	return l, true
+}
+
 // Equiv returns whether a and b are equivalent, that is if they are either equal or comparable.
 func Equiv(a, b Lit) bool {
 	if res, ok := checkNil(a, b); !ok {
 		return res
 	}
+	if c, ok := compNumer(a, b); ok {
+		return c == 0
+	}
 	a, b, ok := comparable(a, b)
 	return ok && Equal(a, b)
 }
@@ -59,6 +77,9 @@ func Comp(a, b Lit) (less, same, ok bool) {
 	if b == nil {
 		b = Nil
 	}
+	if c, ok := compNumer(a, b); ok {
+		return c < 0, c == 0, true
+	}
 	a, b, ok = comparable(a, b)
 	if !ok || !a.Typ().Ordered() || !b.Typ().Ordered() {
 		return false, false, false
@@ -139,12 +160,9 @@ func equalNumer(a, b Numeric) bool {
 	case bool:
 		w, ok := b.Val().(bool)
 		return ok && v == w
-	case int64:
-		w, ok := b.Val().(int64)
-		return ok && v == w
-	case float64:
-		w, ok := b.Val().(float64)
-		return ok && v == w
+	case int64, float64:
+		c, ok := compNum(v, b.Val())
+		return ok && c == 0
 	case time.Time:
 		w, ok := b.Val().(time.Time)
 		return ok && v.Equal(w)
@@ -155,6 +173,45 @@ func equalNumer(a, b Numeric) bool {
 	return false
 }
 
+// compNumer compares numeric literals a and b with int or float values exactly without converting
+// them to a common type. It returns false if either is not such a literal or is NaN.
+func compNumer(a, b Lit) (int, bool) {
+	v, ok := a.(Numeric)
+	if !ok || !v.Typ().Ordered() {
+		return 0, false
+	}
+	w, ok := b.(Numeric)
+	if !ok || !w.Typ().Ordered() {
+		return 0, false
+	}
+	return compNum(v.Val(), w.Val())
+}
+
+// compNum compares the int64 or float64 values v and w exactly and returns -1, 0 or 1 and
+// whether both are numbers and neither is NaN.
+func compNum(v, w interface{}) (int, bool) {
+	switch a := v.(type) {
+	case int64:
+		switch b := w.(type) {
+		case int64:
+			return orderInt(a, b), true
+		case float64:
+			return -orderFloatInt(b, a), !math.IsNaN(b)
+		}
+	case float64:
+		if math.IsNaN(a) {
+			return 0, false
+		}
+		switch b := w.(type) {
+		case int64:
+			return orderFloatInt(a, b), true
+		case float64:
+			return orderFloat(a, b), !math.IsNaN(b)
+		}
+	}
+	return 0, false
+}
+
 func equalCharer(a, b Character) bool {
 	switch v := a.Val().(type) {
 	case string:
@@ -0,0 +1,38 @@
+package lit
+
+import "testing"
+
+func TestCompNumeral(t *testing.T) {
+	big := numeral("9007199254740993")
+	tests := []struct {
+		a, b  Lit
+		less  bool
+		same  bool
+		order int
+	}{
+		{Num(5), big, true, false, -1},
+		{big, Num(5), false, false, 1},
+		{Int(5), big, true, false, -1},
+		{big, Int(5), false, false, 1},
+		{big, Num(9007199254740992), false, false, 1},
+		{Num(9007199254740992), big, true, false, -1},
+		{big, Int(9007199254740993), false, true, 0},
+		{numeral("12345678901234567.5"), Int(5), false, false, 1},
+	}
+	for _, test := range tests {
+		less, same, ok := Comp(test.a, test.b)
+		if !ok || less != test.less || same != test.same {
+			t.Errorf("comp %s %s want %v %v got %v %v %v",
+				test.a, test.b, test.less, test.same, less, same, ok)
+		}
+		if got := Order(test.a, test.b); got != test.order {
+			t.Errorf("order %s %s want %d got %d", test.a, test.b, test.order, got)
+		}
+		if got := Equiv(test.a, test.b); got != test.same {
+			t.Errorf("equiv %s %s want %v got %v", test.a, test.b, test.same, got)
+		}
+	}
+	if Equal(big, Num(9007199254740992)) || !Equal(numeral("1e20"), Num(1e20)) {
+		t.Errorf("want numerals only equal to the exact num")
+	}
+}
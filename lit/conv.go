@@ -31,6 +31,17 @@ func Convert(l Lit, dst typ.Type, cmp typ.Cmp) (_ Lit, err error) {
 			l = Zero(t)
 		}
 	}
+	if s, ok := l.(*Seq); ok {
+		switch cmp &^ typ.BitWrap {
+		case typ.CmpSame, typ.CmpInfer, typ.CmpCompAny:
+		default:
+			l, err = convSeq(s, dst)
+			if err == nil && cmp&typ.BitWrap != 0 {
+				l = Some{l}
+			}
+			return l, err
+		}
+	}
 	switch cmp &^ typ.BitWrap {
 	case typ.CmpSame, typ.CmpInfer:
 	case typ.CmpCompAny:
@@ -80,6 +91,30 @@ func convBase(l Lit, to typ.Type) (Lit, error) {
 	}
 	return nil, cor.Errorf("%v %T to base", ErrUnconv, l)
 }
+
+// convSeq returns a sequence converting each element for list types and otherwise converts
+// the forced list of elements to type to.
+func convSeq(s *Seq, to typ.Type) (Lit, error) {
+	t, _ := to.Deopt()
+	if t.Kind&typ.MaskElem != typ.KindList {
+		l, err := s.List()
+		if err != nil {
+			return nil, err
+		}
+		return Convert(l, t, 0)
+	}
+	el := t.Elem()
+	return NewSeq(el, func(yield func(Lit) error) error {
+		return s.IterIdx(func(_ int, e Lit) error {
+			c, err := Convert(e, el, 0)
+			if err != nil {
+				return err
+			}
+			return yield(c)
+		})
+	}), nil
+}
+
 func convList(l Lit) (Lit, error) {
 	if v, ok := l.(Indexer); ok {
 		res := make([]Lit, v.Len())
@@ -128,6 +163,18 @@ func checkAny(l Lit, to typ.Type) (Lit, error) {
 }
 func checkSpec(l Lit, to typ.Type) (res Lit, err error) {
 	switch v := l.(type) {
+	case Numeral:
+		switch to.Kind & typ.MaskElem {
+		case typ.KindInt:
+			n, err := v.Int()
+			if err != nil {
+				return nil, err
+			}
+			return Int(n), nil
+		case typ.KindReal:
+			return Real(v.Num()), nil
+		}
+		return checkSpec(Num(v.Num()), to)
 	case Numeric:
 		n := v.Num()
 		switch to.Kind & typ.MaskElem {
@@ -0,0 +1,347 @@
+package lit
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lex"
+	"github.com/mb0/xelf/typ"
+)
+
+// Decoder reads literals from a token stream and assigns them directly to proxies.
+//
+// Unlike Read it does not build a syntax tree. Lists are decoded into appenders, dicts into
+// dictionaries and both lists and dicts into records element by element. Values for any other
+// proxy are parsed as generic literal and then converted and assigned.
+type Decoder struct {
+	lex *lex.Lexer
+}
+
+// DecodeDepth is the default nesting depth limit of decoders.
+const DecodeDepth = 1000
+
+// NewDecoder returns a new decoder reading from r. The decoder limits the nesting depth to
+// DecodeDepth, because it decodes nested literals recursively.
+func NewDecoder(r io.Reader) *Decoder {
+	l := lex.New(r)
+	l.Depth = DecodeDepth
+	return &Decoder{lex: l}
+}
+
+// SetLimits sets the resource limits used to decode untrusted input. A zero depth disables the
+// default nesting depth limit. The token limit applies to each decoded value.
+func (d *Decoder) SetLimits(lim lex.Limits) { d.lex.Limits = lim }
+
+// Decode reads one literal from r and assigns it to the proxy p or returns an error.
+func Decode(r io.Reader, p Proxy) error {
+	return NewDecoder(r).Decode(p)
+}
+
+// Decode reads the next literal and assigns it to the proxy p or returns an error.
+func (d *Decoder) Decode(p Proxy) error {
+	d.lex.ResetCount()
+	t, err := d.lex.Token()
+	if err != nil {
+		return err
+	}
+	return d.decode(t, p)
+}
+
+// DecodeType reads the next literal and returns it as literal of type t or an error.
+func (d *Decoder) DecodeType(t typ.Type) (Lit, error) {
+	p := ZeroProxy(t)
+	err := d.Decode(p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (d *Decoder) decode(t lex.Token, p Proxy) (err error) {
+	if s, ok := p.(SomeProxy); ok && t.Tok != lex.Symbol {
+		p = s.Proxy
+	}
+	switch t.Tok {
+	case '[':
+		switch v := p.(type) {
+		case Record:
+			return d.decodeTuple(v)
+		case Appender:
+			return d.decodeList(v, p)
+		}
+	case '{':
+		switch v := p.(type) {
+		case Record:
+			return d.decodeRec(v)
+		case Dictionary:
+			return d.decodeDict(v)
+		}
+	}
+	l, err := d.parse(t)
+	if err != nil {
+		return err
+	}
+	l, err = Convert(l, p.Typ(), 0)
+	if err != nil {
+		return err
+	}
+	return p.Assign(l)
+}
+
+func (d *Decoder) decodeList(v Appender, p Proxy) error {
+	for n := 1; ; n++ {
+		t, err := d.elem()
+		if err != nil {
+			return err
+		}
+		if t.Tok == ']' {
+			break
+		}
+		if err = d.lex.CheckLen(t, n); err != nil {
+			return err
+		}
+		el, err := v.Element()
+		if err != nil {
+			return err
+		}
+		err = d.decode(t, el)
+		if err != nil {
+			return err
+		}
+		if appendPtr(v, el) {
+			continue
+		}
+		v, err = v.Append(decoded(el))
+		if err != nil {
+			return err
+		}
+	}
+	if Lit(v) != Lit(p) {
+		return p.Assign(v)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeTuple(v Record) error {
+	for i := 0; ; i++ {
+		t, err := d.elem()
+		if err != nil {
+			return err
+		}
+		if t.Tok == ']' {
+			return nil
+		}
+		if err = d.lex.CheckLen(t, i+1); err != nil {
+			return err
+		}
+		el, err := v.Idx(i)
+		if err != nil {
+			return err
+		}
+		if p, ok := el.(Proxy); ok {
+			err = d.decode(t, p)
+		} else {
+			err = d.decodeField(t, v, i, "")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Decoder) decodeRec(v Record) error {
+	for n := 1; ; n++ {
+		key, ok, err := d.key()
+		if err != nil || !ok {
+			return err
+		}
+		t, err := d.lex.Token()
+		if err != nil {
+			return err
+		}
+		if err = d.lex.CheckLen(t, n); err != nil {
+			return err
+		}
+		el, err := v.Key(key)
+		if err != nil {
+			return err
+		}
+		if p, ok := el.(Proxy); ok {
+			err = d.decode(t, p)
+		} else {
+			err = d.decodeField(t, v, -1, key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodeField decodes a field that is not accessible as proxy and sets it either by idx or key.
+func (d *Decoder) decodeField(t lex.Token, v Record, idx int, key string) (err error) {
+	rt := v.Typ()
+	var f *typ.Param
+	if key != "" {
+		f, _, err = rt.ParamByKey(key)
+	} else {
+		f, err = rt.ParamByIdx(idx)
+	}
+	if err != nil {
+		return err
+	}
+	p := ZeroProxy(f.Type)
+	err = d.decode(t, p)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		_, err = v.SetKey(key, decoded(p))
+	} else {
+		_, err = v.SetIdx(idx, decoded(p))
+	}
+	return err
+}
+
+func (d *Decoder) decodeDict(v Dictionary) error {
+	for n := 1; ; n++ {
+		key, ok, err := d.key()
+		if err != nil || !ok {
+			return err
+		}
+		t, err := d.lex.Token()
+		if err != nil {
+			return err
+		}
+		if err = d.lex.CheckLen(t, n); err != nil {
+			return err
+		}
+		el, err := v.Element()
+		if err != nil {
+			return err
+		}
+		err = d.decode(t, el)
+		if err != nil {
+			return err
+		}
+		_, err = v.SetKey(key, decoded(el))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parse returns a generic literal starting with token t or an error.
+func (d *Decoder) parse(t lex.Token) (Lit, error) {
+	switch t.Tok {
+	case '[':
+		res := &List{}
+		for {
+			t, err := d.elem()
+			if err != nil {
+				return nil, err
+			}
+			if t.Tok == ']' {
+				return res, nil
+			}
+			if err = d.lex.CheckLen(t, len(res.Data)+1); err != nil {
+				return nil, err
+			}
+			el, err := d.parse(t)
+			if err != nil {
+				return nil, err
+			}
+			res.Data = append(res.Data, el)
+		}
+	case '{':
+		res := &Dict{}
+		for {
+			key, ok, err := d.key()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return res, nil
+			}
+			t, err := d.lex.Token()
+			if err != nil {
+				return nil, err
+			}
+			if err = d.lex.CheckLen(t, len(res.List)+1); err != nil {
+				return nil, err
+			}
+			el, err := d.parse(t)
+			if err != nil {
+				return nil, err
+			}
+			res.List = append(res.List, Keyed{key, el})
+		}
+	}
+	return Parse(&lex.Tree{Token: t})
+}
+
+// elem returns the next token skipping element separators or an error.
+func (d *Decoder) elem() (lex.Token, error) {
+	t, err := d.lex.Token()
+	for err == nil && t.Tok == ',' {
+		t, err = d.lex.Token()
+	}
+	return t, err
+}
+
+// key reads the next key and key separator. It returns false at the end of a dict or an error.
+func (d *Decoder) key() (key string, _ bool, err error) {
+	t, err := d.elem()
+	if err != nil {
+		return "", false, err
+	}
+	switch t.Tok {
+	case '}':
+		return "", false, nil
+	case lex.Symbol:
+		key = t.Raw
+	case lex.String:
+		key, err = cor.Unquote(t.Raw)
+		if err != nil {
+			return "", false, err
+		}
+	default:
+		return "", false, lex.ErrorAt(t, ErrKey)
+	}
+	t, err = d.lex.Token()
+	if err != nil {
+		return "", false, err
+	}
+	if t.Tok != ':' {
+		return "", false, lex.ErrorAt(t, ErrKeySep)
+	}
+	return key, true, nil
+}
+
+// appendPtr appends the value of the element proxy el directly to the go slice behind the
+// appender v if both point to matching go types. It returns false otherwise.
+// This avoids copying the slice and converting the element, like a call to Append would.
+func appendPtr(v Appender, el Proxy) bool {
+	vp, ok := v.(Proxy)
+	if !ok {
+		return false
+	}
+	sp, ep := reflect.ValueOf(vp.Ptr()), reflect.ValueOf(el.Ptr())
+	if sp.Kind() != reflect.Ptr || sp.IsNil() || ep.Kind() != reflect.Ptr || ep.IsNil() {
+		return false
+	}
+	s := sp.Elem()
+	if s.Kind() != reflect.Slice || s.Type().Elem() != ep.Type().Elem() {
+		return false
+	}
+	s.Set(reflect.Append(s, ep.Elem()))
+	return true
+}
+
+// decoded returns the literal of any proxies and otherwise p.
+func decoded(p Proxy) Lit {
+	if a, ok := p.(*AnyProxy); ok {
+		return a.Lit
+	}
+	return p
+}
@@ -136,6 +136,13 @@ type Dictionary interface {
 	Element() (Proxy, error)
 }
 
+// Deleter is the interface for keyer literals that can remove elements.
+type Deleter interface {
+	Keyer
+	// Delete removes the element with key and returns whether it was found.
+	Delete(key string) bool
+}
+
 // Record is the interface for record literals.
 type Record interface {
 	Lit
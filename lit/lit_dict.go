@@ -16,11 +16,22 @@ type Keyed struct {
 }
 
 // Dict is a generic container implementing the dict type.
+//
+// Dict preserves the insertion order of keys. A hash index is built lazily by SetKey for dicts
+// with at least dictIndexMin elements and used for key lookups. The list can still be changed
+// directly. Elements appended to the list are found, but lookups trust the index on a miss, so
+// call Reindex after changing or removing keys of existing elements directly.
 type Dict struct {
 	Elem typ.Type
 	List []Keyed
+	// idx maps the keys of the first idxN elements in list to their position.
+	idx  map[string]int
+	idxN int
 }
 
+// dictIndexMin is the minimum number of elements before a dict builds a hash index.
+const dictIndexMin = 32
+
 // MakeDict returns a new abstract dict literal with the given type or an error.
 func MakeDict(t typ.Type) (*Dict, error) {
 	return MakeDictCap(t, 0)
@@ -32,7 +43,7 @@ func MakeDictCap(t typ.Type, cap int) (*Dict, error) {
 		return nil, typ.ErrInvalid
 	}
 	list := make([]Keyed, 0, cap)
-	return &Dict{t.Elem(), list}, nil
+	return &Dict{Elem: t.Elem(), List: list}, nil
 }
 
 func (d *Dict) Typ() typ.Type           { return typ.Dict(d.Elem) }
@@ -59,10 +70,8 @@ func (d *Dict) Key(k string) (Lit, error) {
 	if d == nil {
 		return Nil, nil
 	}
-	for _, v := range d.List {
-		if v.Key == k {
-			return v.Lit, nil
-		}
+	if i := d.find(k); i >= 0 {
+		return d.List[i].Lit, nil
 	}
 	if d.Elem != typ.Void {
 		return Null(d.Elem), nil
@@ -82,20 +91,80 @@ func (d *Dict) SetKey(k string, el Lit) (_ Keyer, err error) {
 			return d, err
 		}
 	}
-	for i, v := range d.List {
-		if v.Key == k {
-			if el != nil {
-				d.List[i].Lit = el
-			} else {
-				d.List = append(d.List[:i], d.List[i+1:]...)
-			}
-			return d, nil
+	d.reindex()
+	if i := d.find(k); i >= 0 {
+		if el != nil {
+			d.List[i].Lit = el
+		} else {
+			d.List = append(d.List[:i], d.List[i+1:]...)
+			d.idx = nil
 		}
+		return d, nil
+	}
+	if d.idx != nil && d.idxN == len(d.List) {
+		d.idx[k] = d.idxN
+		d.idxN++
 	}
 	d.List = append(d.List, Keyed{k, el})
 	return d, nil
 }
 
+// Delete removes the element with key k and returns whether it was found.
+func (d *Dict) Delete(k string) bool {
+	if d == nil {
+		return false
+	}
+	i := d.find(k)
+	if i < 0 {
+		return false
+	}
+	d.List = append(d.List[:i], d.List[i+1:]...)
+	d.idx = nil
+	return true
+}
+
+// Reindex drops the hash index, it is rebuilt by the next call to SetKey.
+func (d *Dict) Reindex() { d.idx, d.idxN = nil, 0 }
+
+// find returns the position of the first element with key k or -1. It does not modify the index
+// and falls back to a linear scan for stale index hits. On misses it only scans the elements not
+// covered by the index.
+func (d *Dict) find(k string) int {
+	start := 0
+	if d.idx != nil && d.idxN <= len(d.List) {
+		i, ok := d.idx[k]
+		if ok && d.List[i].Key == k {
+			return i
+		}
+		if !ok {
+			start = d.idxN
+		}
+	}
+	for i := start; i < len(d.List); i++ {
+		if d.List[i].Key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// reindex builds or updates the hash index for dicts with at least dictIndexMin elements.
+func (d *Dict) reindex() {
+	if len(d.List) < dictIndexMin {
+		d.idx = nil
+		return
+	}
+	if d.idx == nil || d.idxN > len(d.List) {
+		d.idx, d.idxN = make(map[string]int, len(d.List)), 0
+	}
+	for ; d.idxN < len(d.List); d.idxN++ {
+		k := d.List[d.idxN].Key
+		if _, ok := d.idx[k]; !ok {
+			d.idx[k] = d.idxN
+		}
+	}
+}
+
 func (d *Dict) IterKey(it func(string, Lit) error) error {
 	if d == nil {
 		return nil
@@ -141,7 +210,7 @@ func (d *Dict) Assign(l Lit) error {
 	}
 	switch ld := Deopt(l).(type) {
 	case *Dict:
-		*d = *ld
+		*d = Dict{Elem: ld.Elem, List: ld.List}
 	case Keyer:
 		res := d.List[:0]
 		err := ld.IterKey(func(k string, e Lit) error {
@@ -151,7 +220,7 @@ func (d *Dict) Assign(l Lit) error {
 		if err != nil {
 			return err
 		}
-		d.List = res
+		d.List, d.idx = res, nil
 	default:
 		return cor.Errorf("%q %T not assignable to %q", l.Typ(), l, d.Typ())
 	}
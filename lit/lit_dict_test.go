@@ -0,0 +1,114 @@
+package lit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestDictIndex(t *testing.T) {
+	d := &Dict{}
+	n := 3 * dictIndexMin
+	for i := 0; i < n; i++ {
+		_, err := d.SetKey(fmt.Sprintf("k%d", i), Num(i))
+		if err != nil {
+			t.Fatalf("set key err: %v", err)
+		}
+	}
+	if d.idx == nil || d.idxN != n {
+		t.Errorf("want index with %d entries got %d", n, d.idxN)
+	}
+	d.SetKey("k5", Num(-5))
+	d.List = append(d.List, Keyed{"extra", Num(1)})
+	if d.Len() != n+1 || d.List[5].Lit != Num(-5) {
+		t.Errorf("want order preserved got %s", d)
+	}
+	for _, k := range []string{"k0", "k5", "k95", "extra"} {
+		l, err := d.Key(k)
+		if err != nil || l == Nil {
+			t.Errorf("key %s got %v %v", k, l, err)
+		}
+	}
+	if l, _ := d.Key("missing"); l != Nil {
+		t.Errorf("want nil for missing key got %s", l)
+	}
+	// change keys in the list directly
+	for i := range d.List {
+		d.List[i].Key = fmt.Sprintf("n%d", i)
+	}
+	if l, _ := d.Key("n3"); l != Nil {
+		t.Errorf("want index miss for changed key without reindex got %s", l)
+	}
+	d.Reindex()
+	if l, _ := d.Key("n3"); l != Num(3) {
+		t.Errorf("want 3 for changed key got %s", l)
+	}
+	if l, _ := d.Key("k3"); l != Nil {
+		t.Errorf("want nil for changed key got %s", l)
+	}
+	if !d.Delete("n4") || d.Delete("k4") {
+		t.Errorf("want delete of changed key only")
+	}
+	d.SetKey("n3", Num(-3))
+	if l, _ := d.Key("n3"); l != Num(-3) || d.Len() != n {
+		t.Errorf("want n3 replaced got %s with len %d", l, d.Len())
+	}
+	d.SetKey("n3", Num(3))
+	for i := range d.List {
+		d.List[i].Key = fmt.Sprintf("k%d", i)
+	}
+	d.Reindex()
+	// replace the list directly with a shorter one
+	d.List = d.List[:dictIndexMin/2]
+	if l, _ := d.Key("k3"); l != Num(3) {
+		t.Errorf("want 3 got %s", l)
+	}
+	if l, _ := d.Key("k40"); l != Nil {
+		t.Errorf("want nil for removed key got %s", l)
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "k%d:%d ", i, i)
+	}
+	b.WriteByte('}')
+	l, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	if p := l.(*Dict); p.idxN != n {
+		t.Errorf("want parsed dict index got %d", p.idxN)
+	}
+}
+
+func BenchmarkDictSetKey(b *testing.B) {
+	keys := make([]string, 5000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	for i := 0; i < b.N; i++ {
+		d := &Dict{}
+		for j, k := range keys {
+			d.SetKey(k, Num(j))
+		}
+	}
+}
+
+func TestRecDelete(t *testing.T) {
+	r, err := MakeRec(typ.Rec([]typ.Param{{Name: "a", Type: typ.Int}, {Name: "b", Type: typ.Int}}))
+	if err != nil {
+		t.Fatalf("make rec err: %v", err)
+	}
+	var d Deleter = r
+	if d.Delete("a") {
+		t.Errorf("want record delete to return false")
+	}
+	if got := r.String(); got != "{a:0 b:0}" {
+		t.Errorf("want record unchanged got %s", got)
+	}
+	if _, err := r.Idx(1); err != nil {
+		t.Errorf("want second field got %v", err)
+	}
+}
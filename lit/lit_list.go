@@ -116,6 +116,12 @@ func (l *List) Assign(val Lit) error {
 	switch v := Deopt(val).(type) {
 	case *List:
 		*l = *v
+	case *Seq:
+		res, err := v.List()
+		if err != nil {
+			return err
+		}
+		l.Data = res.Data
 	case Indexer:
 		res := l.Data[:0]
 		err := v.IterIdx(func(i int, e Lit) error {
@@ -1,6 +1,8 @@
 package lit
 
 import (
+	"math"
+	"math/big"
 	"strconv"
 
 	"github.com/mb0/xelf/bfr"
@@ -98,6 +100,115 @@ func (v *Real) Assign(l Lit) error {
 	return cor.Errorf("%q not assignable to %q", l.Typ(), v.Typ())
 }
 
+// Numeral is an untyped numeric literal that keeps the number text as read.
+// Parse returns numerals for numbers that float64 cannot hold exactly, that is integers beyond
+// the float64 precision and decimals with more significant digits than survive a float64
+// round-trip. The text is parsed once by MakeNumeral. Conversion to int is either exact or fails
+// with an error, conversion to real rounds to the nearest float64.
+type Numeral struct {
+	Raw   string
+	num   float64
+	int   int64
+	exact bool
+}
+
+// MakeNumeral parses the number text raw and returns a numeral or an error.
+func MakeNumeral(raw string) (Numeral, error) {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Numeral{}, err
+	}
+	return makeNumeral(raw, f), nil
+}
+
+func makeNumeral(raw string, f float64) Numeral {
+	res := Numeral{Raw: raw, num: f}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		res.int, res.exact = n, true
+	} else if math.Abs(f) <= math.MaxInt64 {
+		// the magnitude is checked first to avoid big exponents
+		r, ok := new(big.Rat).SetString(raw)
+		if ok && r.IsInt() && r.Num().IsInt64() {
+			res.int, res.exact = r.Num().Int64(), true
+		}
+	}
+	return res
+}
+
+func (v Numeral) Typ() typ.Type                { return typ.Num }
+func (v Numeral) IsZero() bool                 { return v.num == 0 }
+func (v Numeral) Num() float64                 { return v.num }
+func (v Numeral) String() string               { return v.Raw }
+func (v Numeral) MarshalJSON() ([]byte, error) { return []byte(v.Raw), nil }
+
+func (v Numeral) WriteBfr(b *bfr.Ctx) error {
+	_, err := b.WriteString(v.Raw)
+	return err
+}
+
+// Val returns an int64 if the numeral has an exact int representation and otherwise a float64.
+func (v Numeral) Val() interface{} {
+	if v.exact {
+		return v.int
+	}
+	return v.num
+}
+
+// Int returns the numeral as int64 or an error if it has no exact int64 representation.
+func (v Numeral) Int() (int64, error) {
+	if v.exact {
+		return v.int, nil
+	}
+	if math.Abs(v.num) >= math.MaxInt64 {
+		return 0, cor.Errorf("numeral %s overflows int", v.Raw)
+	}
+	return 0, cor.Errorf("numeral %s has no exact int representation", v.Raw)
+}
+
+// maxExactDigits is the number of significant decimal digits that survive a float64 round-trip.
+const maxExactDigits = 15
+
+// parseNum returns a num literal for raw or a numeral if float64 cannot hold it exactly.
+func parseNum(raw string) (Lit, error) {
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	if !floatExact(raw, n) {
+		return makeNumeral(raw, n), nil
+	}
+	return Num(n), nil
+}
+
+// floatExact returns whether f parsed from the number text raw round-trips its digits and holds
+// the exact value for integers beyond the float64 precision.
+func floatExact(raw string, f float64) bool {
+	if sigDigits(raw) > maxExactDigits {
+		return false
+	}
+	if math.Abs(f) < 1<<53 {
+		return true
+	}
+	r, ok := new(big.Rat).SetString(raw)
+	return ok && r.Cmp(new(big.Rat).SetFloat64(f)) == 0
+}
+
+// sigDigits returns the number of significant digits in the mantissa of the number text raw.
+func sigDigits(raw string) (n int) {
+	lead := true
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case c == 'e' || c == 'E':
+			return n
+		case c == '0' && lead:
+		case c >= '0' && c <= '9':
+			lead = false
+			n++
+		}
+	}
+	return n
+}
+
 func boolToFloat(b bool) float64 {
 	if b {
 		return 1
@@ -57,6 +57,10 @@ func (a *Rec) Key(key string) (Lit, error) {
 	}
 	return a.Dict.Key(key)
 }
+
+// Delete returns false, because record fields cannot be removed.
+func (a *Rec) Delete(k string) bool { return false }
+
 func (a *Rec) SetIdx(i int, el Lit) (Indexer, error) {
 	f, err := a.Type.ParamByIdx(i)
 	if err != nil {
@@ -107,6 +111,17 @@ func (a *Rec) IterIdx(it func(int, Lit) error) error {
 func (a *Rec) String() string               { return bfr.String(a) }
 func (a *Rec) MarshalJSON() ([]byte, error) { return bfr.JSON(a) }
 func (a *Rec) WriteBfr(b *bfr.Ctx) error {
+	if b.Tuple {
+		els := make([]Lit, 0, len(a.Type.Params))
+		for i := range a.Type.Params {
+			el, err := a.Idx(i)
+			if err != nil {
+				return err
+			}
+			els = append(els, el)
+		}
+		return WriteTuple(b, a.Type.Params, els)
+	}
 	b.WriteByte('{')
 	n := 0
 	for i, f := range a.Type.Params {
@@ -138,3 +153,26 @@ func (a *Rec) Assign(l Lit) error {
 	}
 	return a.Dict.Assign(c)
 }
+
+// WriteTuple writes the record fields ps with values els as list to b. Trailing zero optional
+// fields are omitted and other zero optional fields written as null.
+func WriteTuple(b *bfr.Ctx, ps []typ.Param, els []Lit) error {
+	end := len(els)
+	for end > 0 && ps[end-1].Opt() && (els[end-1] == nil || els[end-1].IsZero()) {
+		end--
+	}
+	b.WriteByte('[')
+	for i, el := range els[:end] {
+		if i > 0 {
+			b.Sep()
+		}
+		if ps[i].Opt() && el != nil && el.IsZero() {
+			el = nil
+		}
+		err := writeLit(b, el)
+		if err != nil {
+			return err
+		}
+	}
+	return b.WriteByte(']')
+}
@@ -0,0 +1,97 @@
+package lit
+
+import (
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/typ"
+)
+
+// Seq is a lazy sequence literal with a list type backed by an iterator function.
+// The elements are only produced when the sequence is iterated or forced into a list. Writing
+// the sequence iterates it. Sequences are not indexers, because that would require them to be
+// materialized. Use List to force a sequence.
+//
+// Errors of the iterator are only returned when the sequence is iterated, written or forced.
+// Clone and Equal force sequences, Convert to a list type returns a lazy converting sequence.
+type Seq struct {
+	Elem typ.Type
+	iter func(yield func(Lit) error) error
+}
+
+// NewSeq returns a new sequence with element type elem backed by the iterator function iter.
+// The iterator calls yield for each element in order and must stop and return any error returned
+// by yield. Each iteration of the sequence calls iter again.
+func NewSeq(elem typ.Type, iter func(yield func(Lit) error) error) *Seq {
+	return &Seq{Elem: elem, iter: iter}
+}
+
+// ChanSeq returns a new sequence with element type elem backed by the channel ch.
+// The elements received from the channel are consumed and cannot be iterated again. A new
+// iteration continues with the next element received. Iteration ends when ch is closed.
+func ChanSeq(elem typ.Type, ch <-chan Lit) *Seq {
+	return NewSeq(elem, func(yield func(Lit) error) error {
+		for el := range ch {
+			if err := yield(el); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Seq) Typ() typ.Type { return typ.List(s.Elem) }
+
+// IsZero returns whether s has no iterator. It does not iterate the sequence and returns false
+// for all other sequences, even if they produce no elements.
+func (s *Seq) IsZero() bool { return s == nil || s.iter == nil }
+
+// IterIdx iterates over the sequence, calling it with the element index and literal.
+// If it returns an error the iteration is aborted.
+func (s *Seq) IterIdx(it func(int, Lit) error) error {
+	if s.IsZero() {
+		return nil
+	}
+	var i int
+	err := s.iter(func(el Lit) error {
+		if el == nil {
+			el = Nil
+		}
+		if err := it(i, el); err != nil {
+			return err
+		}
+		i++
+		return nil
+	})
+	if err == BreakIter {
+		return nil
+	}
+	return err
+}
+
+// List iterates the sequence and returns all elements in a new list or an error.
+func (s *Seq) List() (*List, error) {
+	res := &List{Elem: s.Elem}
+	err := s.IterIdx(func(_ int, el Lit) error {
+		res.Data = append(res.Data, el)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *Seq) String() string               { return bfr.String(s) }
+func (s *Seq) MarshalJSON() ([]byte, error) { return bfr.JSON(s) }
+func (s *Seq) WriteBfr(b *bfr.Ctx) error {
+	b.WriteByte('[')
+	err := s.IterIdx(func(i int, el Lit) error {
+		if i > 0 {
+			b.Sep()
+		}
+		return writeLit(b, el)
+	})
+	if err != nil {
+		return err
+	}
+	return b.WriteByte(']')
+}
@@ -0,0 +1,86 @@
+package lit
+
+import (
+	"testing"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestChanSeq(t *testing.T) {
+	ch := make(chan Lit, 4)
+	for i := 1; i <= 4; i++ {
+		ch <- Int(i)
+	}
+	close(ch)
+	s := ChanSeq(typ.Int, ch)
+	if got := s.Typ().String(); got != "list|int" {
+		t.Errorf("want list|int got %s", got)
+	}
+	var first []Lit
+	err := s.IterIdx(func(i int, el Lit) error {
+		first = append(first, el)
+		if i == 1 {
+			return BreakIter
+		}
+		return nil
+	})
+	if err != nil || len(first) != 2 {
+		t.Fatalf("want two elements got %v %v", first, err)
+	}
+	var l List
+	err = l.Assign(s)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	if got := l.String(); got != `[3 4]` {
+		t.Errorf("want rest [3 4] got %s", got)
+	}
+	b, err := s.MarshalJSON()
+	if err != nil || string(b) != `[]` {
+		t.Errorf("want consumed seq got %s %v", b, err)
+	}
+}
+
+func TestSeqCloneEqualConvert(t *testing.T) {
+	ch := make(chan Lit, 3)
+	for i := 1; i <= 3; i++ {
+		ch <- Int(i)
+	}
+	close(ch)
+	c, err := Clone(ChanSeq(typ.Int, ch))
+	if err != nil {
+		t.Fatalf("clone err: %v", err)
+	}
+	if l, ok := c.(*List); !ok || l.String() != `[1 2 3]` {
+		t.Fatalf("want forced list clone got %T %s", c, c)
+	}
+	if c.String() != `[1 2 3]` {
+		t.Errorf("want clone to be iterable again got %s", c)
+	}
+	ints := func() *Seq {
+		return NewSeq(typ.Int, func(yield func(Lit) error) error {
+			for i := 1; i <= 3; i++ {
+				if err := yield(Int(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if !Equal(ints(), c) || !Equal(c, ints()) || !Equal(ints(), ints()) {
+		t.Errorf("want seq equal to list")
+	}
+	if Equal(ints(), &List{Elem: typ.Int, Data: []Lit{Int(1)}}) {
+		t.Errorf("want seq not equal to shorter list")
+	}
+	for _, to := range []typ.Type{typ.List(typ.Any), typ.List(typ.Num), typ.Opt(typ.List(typ.Int))} {
+		r, err := Convert(ints(), to, 0)
+		if err != nil {
+			t.Errorf("convert to %s err: %v", to, err)
+			continue
+		}
+		if !r.Typ().Equal(to) || r.String() != `[1 2 3]` {
+			t.Errorf("want converted %s got %s %s", to, r.Typ(), r)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package lit
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mb0/xelf/typ"
+)
+
+// Order returns -1, 0 or 1 if a is less, the same or greater than b in a total order over all
+// literals.
+//
+// Unlike Comp it gives an answer for any two literals. Absent and null values, including empty
+// optionals, sort first. Other literals are grouped by the value they represent in this order:
+// bool, number, span, time, str, raw, uuid, list, keyer and type. Numbers of different kinds are
+// compared by value and NaN sorts before all other numbers. Lists are ordered lexicographically.
+// Dicts and records are ordered field-wise by their sorted keys, comparing key then value.
+// Literals without a known value representation sort last by their string representation.
+func Order(a, b Lit) int {
+	a, b = orderDeopt(a), orderDeopt(b)
+	ra, rb := orderRank(a), orderRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch ra {
+	case rankNull:
+		return 0
+	case rankList:
+		return orderIdxer(a.(Indexer), b.(Indexer))
+	case rankKeyer:
+		return orderKeyer(a.(Keyer), b.(Keyer))
+	case rankType, rankOther:
+		return strings.Compare(a.String(), b.String())
+	}
+	av, bv := a.(valer).Val(), b.(valer).Val()
+	switch v := av.(type) {
+	case bool:
+		w := bv.(bool)
+		if v == w {
+			return 0
+		} else if w {
+			return -1
+		}
+		return 1
+	case int64:
+		if w, ok := bv.(int64); ok {
+			return orderInt(v, w)
+		}
+		return -orderFloatInt(bv.(float64), v)
+	case float64:
+		if w, ok := bv.(float64); ok {
+			return orderFloat(v, w)
+		}
+		return orderFloatInt(v, bv.(int64))
+	case time.Duration:
+		return orderInt(int64(v), int64(bv.(time.Duration)))
+	case time.Time:
+		w := bv.(time.Time)
+		if v.Before(w) {
+			return -1
+		} else if v.After(w) {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(v, bv.(string))
+	case []byte:
+		return bytes.Compare(v, bv.([]byte))
+	case [16]byte:
+		w := bv.([16]byte)
+		return bytes.Compare(v[:], w[:])
+	}
+	return 0
+}
+
+// OrderLess returns whether a sorts before b according to Order.
+func OrderLess(a, b Lit) bool { return Order(a, b) < 0 }
+
+const (
+	rankNull = iota
+	rankBool
+	rankNum
+	rankSpan
+	rankTime
+	rankStr
+	rankRaw
+	rankUUID
+	rankList
+	rankKeyer
+	rankType
+	rankOther
+)
+
+func orderDeopt(l Lit) Lit {
+	if o, ok := l.(Opter); ok {
+		l = o.Some()
+	}
+	if l == nil {
+		return nil
+	}
+	if _, ok := l.(Null); ok {
+		return nil
+	}
+	return l
+}
+
+func orderRank(l Lit) int {
+	switch v := l.(type) {
+	case nil:
+		return rankNull
+	case typ.Type:
+		return rankType
+	case Keyer:
+		return rankKeyer
+	case Indexer:
+		return rankList
+	case valer:
+		switch v.Val().(type) {
+		case nil:
+			return rankNull
+		case bool:
+			return rankBool
+		case int64, float64:
+			return rankNum
+		case time.Duration:
+			return rankSpan
+		case time.Time:
+			return rankTime
+		case string:
+			return rankStr
+		case []byte:
+			return rankRaw
+		case [16]byte:
+			return rankUUID
+		}
+	}
+	return rankOther
+}
+
+func orderInt(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func orderFloat(a, b float64) int {
+	switch an, bn := math.IsNaN(a), math.IsNaN(b); {
+	case an && bn:
+		return 0
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// orderFloatInt compares f and i without losing the precision of large integers.
+func orderFloatInt(f float64, i int64) int {
+	switch {
+	case math.IsNaN(f), f < math.MinInt64:
+		return -1
+	case f >= math.MaxInt64:
+		return 1
+	}
+	t := math.Trunc(f)
+	if c := orderInt(int64(t), i); c != 0 {
+		return c
+	}
+	return orderFloat(f, t)
+}
+
+func orderIdxer(a, b Indexer) int {
+	n, m := a.Len(), b.Len()
+	for i := 0; i < n && i < m; i++ {
+		ae, _ := a.Idx(i)
+		be, _ := b.Idx(i)
+		if c := Order(ae, be); c != 0 {
+			return c
+		}
+	}
+	return orderInt(int64(n), int64(m))
+}
+
+func orderKeyer(a, b Keyer) int {
+	ak, bk := a.Keys(), b.Keys()
+	sort.Strings(ak)
+	sort.Strings(bk)
+	for i := 0; i < len(ak) && i < len(bk); i++ {
+		if c := strings.Compare(ak[i], bk[i]); c != 0 {
+			return c
+		}
+		ae, _ := a.Key(ak[i])
+		be, _ := b.Key(bk[i])
+		if c := Order(ae, be); c != 0 {
+			return c
+		}
+	}
+	return orderInt(int64(len(ak)), int64(len(bk)))
+}
@@ -0,0 +1,55 @@
+package lit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{`null`, `false`, -1},
+		{`false`, `true`, -1},
+		{`true`, `0`, -1},
+		{`1`, `1.0`, 0},
+		{`1`, `1.5`, -1},
+		{`2`, `1.5`, 1},
+		{`9007199254740993`, `9007199254740992.0`, 1},
+		{`100`, `'a'`, -1},
+		{`'a'`, `'b'`, -1},
+		{`'b'`, `[]`, -1},
+		{`[1 2]`, `[1 2 0]`, -1},
+		{`[1 3]`, `[1 2 0]`, 1},
+		{`[]`, `{}`, -1},
+		{`{a:1}`, `{a:1 b:0}`, -1},
+		{`{a:2}`, `{a:1 b:0}`, 1},
+		{`{b:1 a:1}`, `{a:1 b:1}`, 0},
+		{`{a:1}`, `{b:0}`, -1},
+	}
+	for _, test := range tests {
+		a, err := Read(strings.NewReader(test.a))
+		if err != nil {
+			t.Fatalf("read %s: %v", test.a, err)
+		}
+		b, err := Read(strings.NewReader(test.b))
+		if err != nil {
+			t.Fatalf("read %s: %v", test.b, err)
+		}
+		if got := Order(a, b); got != test.want {
+			t.Errorf("order %s %s want %d got %d", test.a, test.b, test.want, got)
+		}
+		if got := Order(b, a); got != -test.want {
+			t.Errorf("order %s %s want %d got %d", test.b, test.a, -test.want, got)
+		}
+	}
+	if got := Order(nil, Null(typ.Int)); got != 0 {
+		t.Errorf("order nil null want 0 got %d", got)
+	}
+	if got := Order(Some{Int(2)}, Real(1.5)); got != 1 {
+		t.Errorf("order opt int want 1 got %d", got)
+	}
+}
@@ -2,10 +2,10 @@ package lit
 
 import (
 	"io"
-	"strconv"
 
 	"github.com/mb0/xelf/cor"
 	"github.com/mb0/xelf/lex"
+	"github.com/mb0/xelf/typ"
 )
 
 var (
@@ -24,15 +24,31 @@ func Read(r io.Reader) (Lit, error) {
 	return Parse(a)
 }
 
+// ReadLimits reads and parses from r with the resource limits lim and returns a literal or an
+// error. It should be used to read untrusted input.
+func ReadLimits(r io.Reader, lim lex.Limits) (Lit, error) {
+	a, err := lex.ReadLimits(r, lim)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(a)
+}
+
+// ReadType reads and parses from r and returns a literal converted to type t or an error.
+// Records can be read from keyed dicts or positional lists, as written with the tuple flag.
+func ReadType(r io.Reader, t typ.Type) (Lit, error) {
+	l, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return Convert(l, t, 0)
+}
+
 // Parse parses the syntax tree a and returns a literal or an error.
 func Parse(a *lex.Tree) (Lit, error) {
 	switch a.Tok {
 	case lex.Number:
-		n, err := strconv.ParseFloat(a.Raw, 64)
-		if err != nil {
-			return nil, err
-		}
-		return Num(n), nil
+		return parseNum(a.Raw)
 	case lex.String:
 		txt, err := cor.Unquote(a.Raw)
 		if err != nil {
@@ -70,7 +86,7 @@ func parseList(tree *lex.Tree, v *List) (*List, error) {
 }
 
 func parseDict(tree *lex.Tree, v *Dict) (*Dict, error) {
-	v.List = make([]Keyed, 0, len(tree.Seq))
+	v.List, v.idx = make([]Keyed, 0, len(tree.Seq)), nil
 	for _, t := range tree.Seq {
 		if t.Tok != lex.Tag || len(t.Seq) < 2 {
 			return nil, lex.ErrorAtPos(t.Pos, ErrKeySep)
@@ -95,5 +111,6 @@ func parseDict(tree *lex.Tree, v *Dict) (*Dict, error) {
 		}
 		v.List = append(v.List, Keyed{key, el})
 	}
+	v.reindex()
 	return v, nil
 }
@@ -1,10 +1,13 @@
 package lit
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/lex"
 	"github.com/mb0/xelf/typ"
 )
 
@@ -21,6 +24,11 @@ func TestRead(t *testing.T) {
 		{Num(-23), `-23`, ``, ``},
 		{Num(0), `0.0`, `0`, `0`},
 		{Num(-0.2), `-0.2`, ``, ``},
+		{Num(123456789012345), `123456789012345`, ``, ``},
+		{numeral("9007199254740993"), `9007199254740993`, ``, ``},
+		{numeral("0.10000000000000000555"), `0.10000000000000000555`, ``, ``},
+		{numeral("123456789012345e4"), `123456789012345e4`, ``, ``},
+		{Num(1e20), `1e20`, `100000000000000000000`, `100000000000000000000`},
 		{Char("test"), `"test"`, `'test'`, ``},
 		{Char("test"), `'test'`, ``, `"test"`},
 		{Char("te\"st"), `'te"st'`, ``, `"te\"st"`},
@@ -85,3 +93,118 @@ func strOr(a, b string) string {
 	}
 	return b
 }
+
+func numeral(raw string) Numeral {
+	n, err := MakeNumeral(raw)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func TestNumeral(t *testing.T) {
+	tests := []struct {
+		raw  string
+		typ  typ.Type
+		want Lit
+	}{
+		{`9007199254740993`, typ.Int, Int(9007199254740993)},
+		{`-9223372036854775808`, typ.Int, Int(-9223372036854775808)},
+		{`9007199254740993.000`, typ.Int, Int(9007199254740993)},
+		{`9223372036854775808`, typ.Int, nil},
+		{`9007199254740993.5`, typ.Int, nil},
+		{`1e400`, typ.Int, nil},
+		{`9007199254740993`, typ.Real, Real(9007199254740992)},
+		{`0.10000000000000000555`, typ.Real, Real(0.1)},
+		{`123456789012345e4`, typ.Int, Int(1234567890123450000)},
+		{`9007199254740993`, typ.Num, numeral("9007199254740993")},
+	}
+	for _, test := range tests {
+		l, err := ReadType(strings.NewReader(test.raw), test.typ)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("%s to %s want error got %s", test.raw, test.typ, l)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s to %s err %v", test.raw, test.typ, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, l) {
+			t.Errorf("%s to %s want %#v got %#v", test.raw, test.typ, test.want, l)
+		}
+	}
+}
+
+func TestTuple(t *testing.T) {
+	rt, err := typ.Read(strings.NewReader(`<rec name:str note?:str qty?:int>`))
+	if err != nil {
+		t.Fatalf("read type: %v", err)
+	}
+	tests := []struct {
+		raw, tup string
+	}{
+		{`{name:'a'}`, `['a']`},
+		{`{name:'b' qty:2}`, `['b' null 2]`},
+		{`{name:'c' note:'x' qty:3}`, `['c' 'x' 3]`},
+	}
+	for _, test := range tests {
+		l, err := ReadType(strings.NewReader(test.raw), rt)
+		if err != nil {
+			t.Errorf("read %s error: %v", test.raw, err)
+			continue
+		}
+		var b strings.Builder
+		err = l.WriteBfr(&bfr.Ctx{B: &b, Tuple: true})
+		if err != nil {
+			t.Errorf("write %s error: %v", test.raw, err)
+			continue
+		}
+		if got := b.String(); got != test.tup {
+			t.Errorf("want tuple %s got %s", test.tup, got)
+		}
+		r, err := ReadType(strings.NewReader(test.tup), rt)
+		if err != nil {
+			t.Errorf("read tuple %s error: %v", test.tup, err)
+			continue
+		}
+		if !Equal(r, l) {
+			t.Errorf("want %s got %s", l, r)
+		}
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	lim := lex.Limits{Depth: 2, Tokens: 32, Str: 10, Len: 3}
+	tests := []struct {
+		raw string
+		err error
+	}{
+		{`{a:[1 2 3] b:'xyz'}`, nil},
+		{`{a:[[1]]}`, lex.ErrDepth},
+		{`{a:[1 2 3 4]}`, lex.ErrLen},
+		{`{a:1 b:2 c:3 d:4}`, lex.ErrLen},
+		{`{a:'0123456789'}`, lex.ErrStrLen},
+	}
+	for _, test := range tests {
+		_, err := ReadLimits(strings.NewReader(test.raw), lim)
+		if !errors.Is(err, test.err) {
+			t.Errorf("read %s want error %v got %v", test.raw, test.err, err)
+		}
+		d := NewDecoder(strings.NewReader(test.raw))
+		d.SetLimits(lim)
+		_, err = d.DecodeType(typ.Dict(typ.Any))
+		if !errors.Is(err, test.err) {
+			t.Errorf("decode %s want error %v got %v", test.raw, test.err, err)
+		}
+	}
+	// limits apply to each value of a stream
+	d := NewDecoder(strings.NewReader(`[1] [2] [3]`))
+	d.SetLimits(lex.Limits{Tokens: 4})
+	for i := 0; i < 3; i++ {
+		if _, err := d.DecodeType(typ.List(typ.Int)); err != nil {
+			t.Fatalf("decode stream value %d err: %v", i, err)
+		}
+	}
+}
@@ -1,14 +1,61 @@
 package lit
 
 import (
+	"strings"
+
 	"github.com/mb0/xelf/cor"
 	"github.com/mb0/xelf/typ"
 )
 
 type PathSeg = typ.PathSeg
+type PathPred = typ.PathPred
 type Path = typ.Path
 
-var ReadPath = typ.ReadPath
+// ReadPath reads and returns the segments for path or an error. Unlike typ.ReadPath it parses
+// the predicate literals once and stores them on the predicates.
+func ReadPath(path string) (Path, error) {
+	p, err := typ.ReadPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p, readPreds(p)
+}
+
+func readPreds(p Path) error {
+	for _, s := range p {
+		if s.Pred == nil {
+			continue
+		}
+		if s.Pred.Op != "" {
+			l, err := Read(strings.NewReader(s.Pred.Raw))
+			if err != nil {
+				return cor.Errorf("predicate %s: %w", s.Pred, err)
+			}
+			s.Pred.Val = l
+		}
+		err := readPreds(s.Pred.Path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// predLit returns the literal of predicate p or an error. Predicates of paths not read by this
+// package are parsed on each call.
+func predLit(p *PathPred) (Lit, error) {
+	if p.Op == "" {
+		return nil, nil
+	}
+	if l, ok := p.Val.(Lit); ok {
+		return l, nil
+	}
+	l, err := Read(strings.NewReader(p.Raw))
+	if err != nil {
+		return nil, cor.Errorf("predicate %s: %w", p, err)
+	}
+	return l, nil
+}
 
 // Select reads path and returns the selected literal from within the container l or an error.
 func Select(l Lit, path string) (Lit, error) {
@@ -25,7 +72,24 @@ func SelectPath(l Lit, p Path) (Lit, error) {
 }
 func selectPath(l Lit, p Path, subs bool) (_ Lit, err error) {
 	for i, s := range p {
-		if s.Sel && (i > 0 || !subs) {
+		if s.Multi() {
+			if t, ok := l.(typ.Type); ok {
+				return typ.SelectPath(t, p[i:])
+			}
+			res, err := selectMulti(l, s)
+			if err != nil {
+				return nil, cor.Errorf("select %s: %w", p, err)
+			}
+			if rest := p[i+1:]; len(rest) > 0 {
+				for j, el := range res {
+					res[j], err = selectPath(el, rest, true)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+			return &List{Data: res}, nil
+		} else if s.Sel && (i > 0 || !subs) {
 			sub := p[i:]
 			var res []Lit
 			switch v := Deopt(l).(type) {
@@ -70,6 +134,178 @@ func selectPath(l Lit, p Path, subs bool) (_ Lit, err error) {
 	return l, nil
 }
 
+// selectMulti returns all elements selected by the multi segment s from l or an error.
+func selectMulti(l Lit, s PathSeg) ([]Lit, error) {
+	l = Deopt(l)
+	if s.Desc {
+		s.Desc = false
+		return selectDesc(l, s, nil)
+	}
+	if s.Slice {
+		v, ok := l.(Indexer)
+		if !ok {
+			return nil, cor.Errorf("slice segment expects idxer got %s", l.Typ())
+		}
+		start, end := s.Idx, s.End
+		n := v.Len()
+		if start < 0 {
+			start += n
+		}
+		if s.Open {
+			end = n
+		} else if end < 0 {
+			end += n
+		}
+		if start < 0 || end > n || start > end {
+			return nil, cor.Errorf("slice %s out of bounds for length %d", s, n)
+		}
+		res := make([]Lit, 0, end-start)
+		for i := start; i < end; i++ {
+			el, err := v.Idx(i)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, el)
+		}
+		return res, nil
+	}
+	els, err := elems(l)
+	if err != nil || s.Pred == nil {
+		return els, err
+	}
+	want, err := predLit(s.Pred)
+	if err != nil {
+		return nil, err
+	}
+	res := els[:0]
+	for _, el := range els {
+		if matchPred(el, s.Pred, want) {
+			res = append(res, el)
+		}
+	}
+	return res, nil
+}
+
+// selectDesc appends all elements selected by s from l and any of its descendants to res.
+func selectDesc(l Lit, s PathSeg, res []Lit) ([]Lit, error) {
+	switch {
+	case s.Multi():
+		if descMulti(l, s) {
+			els, err := selectMulti(l, s)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, els...)
+		}
+	case s.Key != "":
+		if v, ok := l.(Keyer); ok {
+			for _, k := range v.Keys() {
+				if k == s.Key {
+					el, err := v.Key(k)
+					if err != nil {
+						return nil, err
+					}
+					res = append(res, el)
+					break
+				}
+			}
+		}
+	default:
+		if v, ok := l.(Indexer); ok {
+			idx := s.Idx
+			if idx < 0 {
+				idx += v.Len()
+			}
+			if idx >= 0 && idx < v.Len() {
+				el, err := v.Idx(idx)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, el)
+			}
+		}
+	}
+	els, err := elems(l)
+	if err != nil {
+		return res, nil
+	}
+	for _, el := range els {
+		res, err = selectDesc(Deopt(el), s, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// descMulti returns whether the multi segment s applies to the descendant l.
+func descMulti(l Lit, s PathSeg) bool {
+	if s.Slice {
+		_, ok := l.(Indexer)
+		return ok
+	}
+	switch l.(type) {
+	case Indexer, Keyer:
+		return true
+	}
+	return false
+}
+
+// elems returns the element literals of the idxer or keyer l or an error.
+func elems(l Lit) (res []Lit, err error) {
+	switch v := l.(type) {
+	case Indexer:
+		res = make([]Lit, 0, v.Len())
+		err = v.IterIdx(func(_ int, el Lit) error {
+			res = append(res, el)
+			return nil
+		})
+	case Keyer:
+		res = make([]Lit, 0, v.Len())
+		err = v.IterKey(func(_ string, el Lit) error {
+			res = append(res, el)
+			return nil
+		})
+	default:
+		return nil, cor.Errorf("want idxer or keyer got %s", l.Typ())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// matchPred returns whether the element el matches predicate p. Elements without a value at the
+// predicate path or with values not comparable to want never match.
+func matchPred(el Lit, p *typ.PathPred, want Lit) bool {
+	v, err := SelectPath(el, p.Path)
+	if err != nil || v == nil {
+		return false
+	}
+	if p.Op == "" {
+		return !v.IsZero()
+	}
+	switch p.Op {
+	case "=":
+		return Equiv(v, want)
+	case "!=":
+		return !Equiv(v, want)
+	}
+	less, same, ok := Comp(v, want)
+	if !ok {
+		return false
+	}
+	switch p.Op {
+	case "<":
+		return less
+	case "<=":
+		return less || same
+	case ">":
+		return !less && !same
+	}
+	return !less
+}
+
 func SelectKey(l Lit, key string) (Lit, error) {
 	switch v := Deopt(l).(type) {
 	case typ.Type:
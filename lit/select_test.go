@@ -2,6 +2,7 @@ package lit
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -34,3 +35,68 @@ func TestSetPath(t *testing.T) {
 		}
 	}
 }
+
+func TestSelect(t *testing.T) {
+	l, err := Read(strings.NewReader(`{items:[
+		{name:'a' price:5 tags:['x']}
+		{name:'b' price:12 tags:[]}
+		{name:'c' price:20 tags:['y' 'z']}
+	] meta:{name:'m'}}`))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"items.1.name", `'b'`},
+		{"items/name", `['a' 'b' 'c']`},
+		{"items/1:3/name", `['b' 'c']`},
+		{"items/:-1/price", `[5 12]`},
+		{"items/1:/price", `[12 20]`},
+		{"items/1:1/price", `[]`},
+		{"items.*.price", `[5 12 20]`},
+		{"items/[price > 10]/name", `['b' 'c']`},
+		{"items/[price <= 12]/name", `['a' 'b']`},
+		{"items/[name = 'c']/price", `[20]`},
+		{"items/[tags]/name", `['a' 'c']`},
+		{".name", `['a' 'b' 'c' 'm']`},
+		{"items..tags", `[['x'] [] ['y' 'z']]`},
+	}
+	for _, test := range tests {
+		p, err := ReadPath(test.path)
+		if err != nil {
+			t.Errorf("read path %s error: %v", test.path, err)
+			continue
+		}
+		if got := p.String(); got != test.path {
+			t.Errorf("want path string %s got %s", test.path, got)
+		}
+		res, err := SelectPath(l, p)
+		if err != nil {
+			t.Errorf("select %s error: %v", test.path, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("select %s want %s got %s", test.path, test.want, got)
+		}
+	}
+	if _, err := Select(l, "items..[price > {]"); err == nil {
+		t.Errorf("select descent with invalid predicate want error")
+	}
+	ids, err := Read(strings.NewReader(`{items:[{id:9007199254740993} {id:5}]}`))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	res, err := Select(ids, "items/[id > 10]/id")
+	if err != nil || res.String() != `[9007199254740993]` {
+		t.Errorf("select numeral ids want [9007199254740993] got %v %v", res, err)
+	}
+	p, err := ReadPath("items/[price > 10]/name")
+	if err != nil {
+		t.Fatalf("read path error: %v", err)
+	}
+	if v, ok := p[1].Pred.Val.(Lit); !ok || v.String() != "10" {
+		t.Errorf("want parsed predicate literal 10 got %v", p[1].Pred.Val)
+	}
+}
@@ -0,0 +1,173 @@
+package lit
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/typ"
+)
+
+var (
+	ErrRequired     = cor.StrError("required value missing")
+	ErrUnknownField = cor.StrError("unknown field")
+	ErrKind         = cor.StrError("wrong kind")
+	ErrFormat       = cor.StrError("invalid format")
+	ErrConst        = cor.StrError("unknown constant")
+)
+
+// Violation is a problem with the value at a path found during validation.
+// The path uses the select path syntax and is empty for the root value.
+type Violation struct {
+	Path string
+	Err  error
+}
+
+func (v Violation) Error() string {
+	if v.Path == "" {
+		return v.Err.Error()
+	}
+	return v.Path + ": " + v.Err.Error()
+}
+func (v Violation) Unwrap() error { return v.Err }
+
+// Violations is a list of violations and implements the error interface.
+type Violations []Violation
+
+func (vs Violations) Error() string {
+	var b strings.Builder
+	for i, v := range vs {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.Error())
+	}
+	return b.String()
+}
+
+// Validate checks the whole literal l against type t and returns a Violations error or nil.
+//
+// Unlike Convert it does not stop at the first problem. Records report missing required fields
+// and unknown keys, containers are checked element wise and primitive values must be convertible
+// to their type. Enum values must name a constant and bits values must only use known flags.
+// Null values are only valid for optional types, except for record fields that are not required.
+func Validate(l Lit, t typ.Type) error {
+	var res Violations
+	validate(&res, "", l, t)
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func validate(res *Violations, path string, l Lit, t typ.Type) {
+	fail := func(err error) { *res = append(*res, Violation{path, err}) }
+	t, opt := t.Deopt()
+	if l == nil || Deopt(l) == nil {
+		if !opt && t.Kind != typ.KindAny && t.Kind != typ.KindVoid {
+			fail(ErrRequired)
+		}
+		return
+	}
+	l = Deopt(l)
+	switch t.Kind & typ.MaskRef {
+	case typ.KindVoid, typ.KindAny:
+		return
+	case typ.KindRec, typ.KindObj:
+		k, ok := l.(Keyer)
+		if !ok {
+			fail(kindError(l, t))
+			return
+		}
+		keys := make(map[string]bool, k.Len())
+		for _, key := range k.Keys() {
+			keys[key] = true
+		}
+		for _, p := range t.Params {
+			key := p.Key()
+			fpath := joinPath(path, key)
+			if !keys[key] {
+				if !p.Opt() {
+					*res = append(*res, Violation{fpath, ErrRequired})
+				}
+				continue
+			}
+			delete(keys, key)
+			el, err := k.Key(key)
+			if err != nil {
+				*res = append(*res, Violation{fpath, err})
+				continue
+			}
+			if (el == nil || Deopt(el) == nil) && p.Opt() {
+				continue
+			}
+			validate(res, fpath, el, p.Type)
+		}
+		for _, key := range k.Keys() {
+			if keys[key] {
+				*res = append(*res, Violation{joinPath(path, key), ErrUnknownField})
+			}
+		}
+	case typ.KindList:
+		v, ok := l.(Indexer)
+		if _, isKeyer := l.(Keyer); !ok || isKeyer {
+			fail(kindError(l, t))
+			return
+		}
+		v.IterIdx(func(i int, el Lit) error {
+			validate(res, joinPath(path, strconv.Itoa(i)), el, t.Elem())
+			return nil
+		})
+	case typ.KindDict:
+		v, ok := l.(Keyer)
+		if !ok {
+			fail(kindError(l, t))
+			return
+		}
+		v.IterKey(func(k string, el Lit) error {
+			validate(res, joinPath(path, k), el, t.Elem())
+			return nil
+		})
+	case typ.KindEnum:
+		c, ok := l.(Character)
+		if !ok {
+			fail(kindError(l, t))
+		} else if t.HasConsts() {
+			if _, ok := t.Consts.ByKey(strings.ToLower(c.Char())); !ok {
+				fail(cor.Errorf("%w %q for %s", ErrConst, c.Char(), t))
+			}
+		}
+	case typ.KindBits:
+		n, ok := l.(Numeric)
+		if !ok {
+			fail(kindError(l, t))
+		} else if t.HasConsts() {
+			mask := int64(n.Num())
+			for _, c := range t.Consts {
+				mask &^= c.Val
+			}
+			if mask != 0 {
+				fail(cor.Errorf("%w bits %d for %s", ErrConst, mask, t))
+			}
+		}
+	default:
+		if typ.Compare(l.Typ(), t) < typ.LvlCheck {
+			fail(kindError(l, t))
+			return
+		}
+		if _, err := Convert(l, t, 0); err != nil {
+			fail(cor.Errorf("%w for %s: %v", ErrFormat, t, err))
+		}
+	}
+}
+
+func kindError(l Lit, t typ.Type) error {
+	return cor.Errorf("%w expect %s got %s", ErrKind, t, l.Typ())
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
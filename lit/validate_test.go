@@ -0,0 +1,78 @@
+package lit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/typ"
+)
+
+func TestValidate(t *testing.T) {
+	color := typ.Type{typ.KindEnum, &typ.Info{Ref: "color", Consts: []typ.Const{
+		{Name: "Red", Val: 1}, {Name: "Green", Val: 2},
+	}}}
+	item := typ.Rec([]typ.Param{
+		{Name: "name", Type: typ.Str},
+		{Name: "color?", Type: color},
+	})
+	order := typ.Rec([]typ.Param{
+		{Name: "id", Type: typ.UUID},
+		{Name: "date", Type: typ.Time},
+		{Name: "note?", Type: typ.Str},
+		{Name: "items", Type: typ.List(item)},
+	})
+	tests := []struct {
+		raw  string
+		want map[string]error
+	}{
+		{`{id:'3d1bd5a6-64f7-4a7f-a04a-4e6f3b9e7b2b' date:'2019-01-17' items:[{name:'a' color:'red'}]}`, nil},
+		{`{id:'3d1bd5a6-64f7-4a7f-a04a-4e6f3b9e7b2b' date:'2019-01-17' note:null items:[]}`, nil},
+		{`{id:'nope' date:'yesterday' extra:1 items:[{name:1} {color:'blue'} 'x']}`, map[string]error{
+			"id":            ErrFormat,
+			"date":          ErrFormat,
+			"extra":         ErrUnknownField,
+			"items.0.name":  ErrKind,
+			"items.1.name":  ErrRequired,
+			"items.1.color": ErrConst,
+			"items.2":       ErrKind,
+		}},
+		{`{note:'x'}`, map[string]error{
+			"id":    ErrRequired,
+			"date":  ErrRequired,
+			"items": ErrRequired,
+		}},
+		{`[]`, map[string]error{"": ErrKind}},
+	}
+	for _, test := range tests {
+		l, err := Read(strings.NewReader(test.raw))
+		if err != nil {
+			t.Errorf("parse %s error: %v", test.raw, err)
+			continue
+		}
+		var vs Violations
+		err = Validate(l, order)
+		if err != nil && !errors.As(err, &vs) {
+			t.Errorf("validate %s want violations got %v", test.raw, err)
+			continue
+		}
+		if len(vs) != len(test.want) {
+			t.Errorf("validate %s want %d violations got %v", test.raw, len(test.want), vs)
+			continue
+		}
+		for _, v := range vs {
+			want, ok := test.want[v.Path]
+			if !ok || !errors.Is(v, want) {
+				t.Errorf("validate %s unexpected violation %v", test.raw, v)
+			}
+		}
+	}
+	if err := Validate(Null(typ.Str), typ.Opt(typ.Str)); err != nil {
+		t.Errorf("validate null for opt str got %v", err)
+	}
+	var vs Violations
+	err := Validate(Null(typ.Str), typ.Str)
+	if !errors.As(err, &vs) || len(vs) != 1 || !errors.Is(vs[0], ErrRequired) {
+		t.Errorf("validate null for str got %v", err)
+	}
+}
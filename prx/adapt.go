@@ -35,6 +35,9 @@ func AdaptValue(val reflect.Value) (lit.Lit, error) {
 			return lit.Null(t), nil
 		}
 	}
+	if mk := marshalKind(t); mk != marshNone {
+		return adaptMarshal(v, mk, ptr)
+	}
 	var l lit.Lit
 	switch t.Kind() {
 	case reflect.Bool:
@@ -50,14 +53,28 @@ func AdaptValue(val reflect.Value) (lit.Lit, error) {
 	case reflect.Int, reflect.Int32:
 		l = lit.Int(v.Int())
 	case reflect.Uint64:
-		// TODO check bits
+		if isRef(t, refBits) {
+			mt, err := ReflectType(t)
+			if err != nil {
+				return nil, err
+			}
+			l = lit.BitsInt{mt, lit.Int(int64(v.Uint()))}
+			break
+		}
 		fallthrough
 	case reflect.Uint, reflect.Uint32:
 		l = lit.Int(int64(v.Uint()))
 	case reflect.Float32, reflect.Float64:
 		l = lit.Real(v.Float())
 	case reflect.String:
-		// TODO check enum
+		if isRef(t, refEnum) {
+			mt, err := ReflectType(t)
+			if err != nil {
+				return nil, err
+			}
+			l = lit.EnumStr{mt, lit.Str(v.String())}
+			break
+		}
 		l = lit.Str(v.String())
 	case reflect.Struct:
 		if v, ok := toRef(t, refTime, v); ok {
@@ -82,6 +99,12 @@ func AdaptValue(val reflect.Value) (lit.Lit, error) {
 		}
 		return adaptArr(v)
 	case reflect.Interface:
+		if hasVariants(t) {
+			if v.IsNil() {
+				return lit.Null(Variant), nil
+			}
+			return adaptVariant(v)
+		}
 		if v.IsNil() {
 			return lit.Nil, nil
 		}
@@ -100,6 +123,25 @@ func AdaptValue(val reflect.Value) (lit.Lit, error) {
 	return l, nil
 }
 
+func adaptMarshal(v reflect.Value, mk marshKind, opt bool) (lit.Lit, error) {
+	t, err := marshalType(v.Type(), mk)
+	if err != nil {
+		return nil, err
+	}
+	var pv reflect.Value
+	if v.CanAddr() {
+		pv = v.Addr()
+	} else { // copy to call methods with pointer receivers
+		pv = reflect.New(v.Type())
+		pv.Elem().Set(v)
+	}
+	l, err := marshalLit(pv, t, mk)
+	if err != nil || !opt || t.Kind == typ.KindAny {
+		return l, err
+	}
+	return lit.Some{l}, nil
+}
+
 func adaptArr(v reflect.Value) (lit.Appender, error) {
 	et, err := ReflectType(v.Type().Elem())
 	if err != nil {
@@ -147,16 +189,19 @@ func adaptMap(v reflect.Value) (lit.Dictionary, error) {
 }
 
 func adaptObj(v reflect.Value) (lit.Record, error) {
-	nfo, idx, err := reflectFields(v.Type(), make(infoMap))
-	if err != nil {
-		return nil, err
+	c := cachedType(v.Type())
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.typ.Kind&typ.KindAny != typ.KindCont {
+		return nil, cor.Errorf("not adaptable %s", v.Type())
 	}
-	res, err := lit.MakeRec(typ.Rec(nfo.Params))
+	res, err := lit.MakeRec(typ.Rec(c.typ.Params))
 	if err != nil {
 		return nil, err
 	}
-	for i, f := range nfo.Params {
-		el, err := AdaptValue(v.FieldByIndex(idx[i]))
+	for i, f := range c.typ.Params {
+		el, err := AdaptValue(v.FieldByIndex(c.idx[i]))
 		if err != nil {
 			return nil, err
 		}
@@ -2,9 +2,11 @@ package prx
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mb0/xelf/bfr"
 	"github.com/mb0/xelf/cor"
 	. "github.com/mb0/xelf/lit"
 	"github.com/mb0/xelf/typ"
@@ -96,3 +98,108 @@ func TestAdaptMod(t *testing.T) {
 	}
 	t.Log(got.String())
 }
+
+type myItem struct {
+	Name string
+	Note string `json:"note,omitempty"`
+	Qty  int    `json:"qty,omitempty"`
+}
+
+func TestTuple(t *testing.T) {
+	items := []myItem{{"a", "", 0}, {"b", "", 2}, {"c", "x", 3}}
+	l, err := Adapt(items)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	var b strings.Builder
+	err = l.WriteBfr(&bfr.Ctx{B: &b, JSON: true, Tuple: true})
+	if err != nil {
+		t.Fatalf("write err: %v", err)
+	}
+	want := `[["a"],["b",null,2],["c","x",3]]`
+	if got := b.String(); got != want {
+		t.Errorf("want %s got %s", want, got)
+	}
+	var res []myItem
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	tl, err := ReadType(strings.NewReader(want), p.Typ())
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	err = p.Assign(tl)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	if !reflect.DeepEqual(res, items) {
+		t.Errorf("want %v got %v", items, res)
+	}
+	var nilItem *myItem
+	np, err := NewProxy(nilItem)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	for _, tup := range []bool{false, true} {
+		want := "{}"
+		if tup {
+			want = "[]"
+		}
+		b.Reset()
+		err = np.WriteBfr(&bfr.Ctx{B: &b, Tuple: tup})
+		if err != nil {
+			t.Fatalf("write nil err: %v", err)
+		}
+		if got := b.String(); got != want {
+			t.Errorf("nil item with tuple %v want %s got %s", tup, want, got)
+		}
+		l, err := ReadType(strings.NewReader(want), np.Typ())
+		if err != nil {
+			t.Fatalf("read nil err: %v", err)
+		}
+		if !l.IsZero() {
+			t.Errorf("want zero item from %s got %s", want, l)
+		}
+	}
+}
+
+func TestAssignZeroRec(t *testing.T) {
+	item := myItem{"a", "x", 1}
+	ptr := &myItem{"b", "y", 2}
+	for _, v := range []interface{}{&item, &ptr} {
+		p, err := NewProxy(v)
+		if err != nil {
+			t.Fatalf("proxy err: %v", err)
+		}
+		err = p.Assign(Null(p.Typ()))
+		if err != nil {
+			t.Fatalf("assign null to %s err: %v", p.Typ(), err)
+		}
+	}
+	if item != (myItem{}) {
+		t.Errorf("want zero item got %v", item)
+	}
+	if ptr == nil || *ptr != (myItem{}) {
+		t.Errorf("want pointer to zero item got %v", ptr)
+	}
+}
+
+func TestHash(t *testing.T) {
+	items := []myItem{{"a", "", 1}, {"b", "x", 2}}
+	p, err := Adapt(items)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	l, err := ReadType(strings.NewReader(`[{name:'a' qty:1} {qty:2 note:'x' name:'b'}]`), p.Typ())
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	hp, err := Hash(p)
+	if err != nil {
+		t.Fatalf("hash err: %v", err)
+	}
+	if hl, _ := Hash(l); hp != hl {
+		t.Errorf("want equal hash for proxy %s and generic %s", p, l)
+	}
+}
@@ -0,0 +1,46 @@
+package prx
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/mb0/xelf/typ"
+)
+
+// cache holds reflected types and struct field indices keyed by reflect type.
+// The cached types share their type info and must not be modified.
+var cache sync.Map
+
+type cached struct {
+	typ typ.Type
+	idx [][]int
+	err error
+}
+
+// ResetCache clears the cache of reflected types and field indices used by all reflection based
+// functions in this package. It is meant for tests that need to reflect types from scratch.
+func ResetCache() {
+	cache.Range(func(k, _ interface{}) bool {
+		cache.Delete(k)
+		return true
+	})
+}
+
+// cachedType returns the cached reflection result for t, reflecting t if necessary.
+func cachedType(t reflect.Type) *cached {
+	if c, ok := cache.Load(t); ok {
+		return c.(*cached)
+	}
+	c := &cached{}
+	c.typ, c.err = reflectType(t, make(infoMap))
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if c.err == nil && st.Kind() == reflect.Struct && c.typ.Kind&typ.KindAny == typ.KindCont &&
+		marshalKind(st) == marshNone {
+		c.idx, c.err = fieldIndices(st, c.typ.Params)
+	}
+	res, _ := cache.LoadOrStore(t, c)
+	return res.(*cached)
+}
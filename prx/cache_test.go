@@ -0,0 +1,80 @@
+package prx
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type cacheItem struct {
+	Name string
+	Tags []string
+}
+
+func TestCache(t *testing.T) {
+	ResetCache()
+	rt := reflect.TypeOf(cacheItem{})
+	a, err := ReflectType(rt)
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	b, err := ReflectType(rt)
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	if a.Info != b.Info {
+		t.Errorf("want cached type info")
+	}
+	p, err := NewProxy(&cacheItem{})
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	if p.Typ().Info != a.Info {
+		t.Errorf("want proxy to use cached type info")
+	}
+	ResetCache()
+	c, err := ReflectType(rt)
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	if c.Info == a.Info || !c.Equal(a) {
+		t.Errorf("want fresh but equal type after reset got %s", c)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	ResetCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				item := cacheItem{Name: "a", Tags: []string{"b"}}
+				p, err := NewProxy(&item)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := p.String(); got != "{name:'a' tags:['b']}" {
+					t.Errorf("unexpected proxy string %s", got)
+					return
+				}
+				if j%50 == 0 {
+					ResetCache()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkNewProxy(b *testing.B) {
+	item := cacheItem{Name: "a", Tags: []string{"b"}}
+	for i := 0; i < b.N; i++ {
+		_, err := NewProxy(&item)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package prx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+)
+
+type cloneItem struct {
+	Name string   `json:"name"`
+	Data []byte   `json:"data"`
+	Tags []string `json:"tags"`
+}
+
+func TestCloneProxy(t *testing.T) {
+	v := cloneItem{"a", []byte("xy"), []string{"t1", "t2"}}
+	p, err := NewProxy(&v)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	c, err := lit.CloneProxy(p)
+	if err != nil {
+		t.Fatalf("clone err: %v", err)
+	}
+	cv, ok := c.Ptr().(*cloneItem)
+	if !ok {
+		t.Fatalf("want *cloneItem got %T", c.Ptr())
+	}
+	if !reflect.DeepEqual(*cv, v) {
+		t.Errorf("want clone %+v got %+v", v, *cv)
+	}
+	cv.Data[0] = 'z'
+	cv.Tags[0] = "t3"
+	if string(v.Data) != "xy" || v.Tags[0] != "t1" {
+		t.Errorf("want original unchanged got %+v", v)
+	}
+}
@@ -0,0 +1,170 @@
+package prx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/lex"
+	. "github.com/mb0/xelf/lit"
+)
+
+type myOrder struct {
+	ID    int
+	Items []myItem
+	Tags  map[string]string
+	Point *myPoint
+}
+
+func TestDecode(t *testing.T) {
+	raw := `{id:7 items:[{name:'a'} {name:'b', qty:2} ["c" "x" 3]]
+		tags:{"a":'b'} point:{x:1 y:2}}`
+	var res myOrder
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = Decode(strings.NewReader(raw), p)
+	if err != nil {
+		t.Fatalf("decode err: %v", err)
+	}
+	want := myOrder{7, []myItem{{"a", "", 0}, {"b", "", 2}, {"c", "x", 3}},
+		map[string]string{"a": "b"}, &myPoint{X: 1, Y: 2}}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("want %+v got %+v", want, res)
+	}
+	_, err = NewDecoder(strings.NewReader(`{id:1 unknown:2}`)).DecodeType(p.Typ())
+	if err == nil {
+		t.Errorf("want error for unknown key")
+	}
+	var lst [][]int
+	lp, err := NewProxy(&lst)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = Decode(strings.NewReader(strings.Repeat("[", DecodeDepth+1)), lp)
+	if !errors.Is(err, lex.ErrDepth) {
+		t.Errorf("want depth error got %v", err)
+	}
+	items := []myItem{{"a", "", 0}}
+	ap, err := NewProxy(&items)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	app, err := ap.(Appender).Append(&Dict{List: []Keyed{{"name", Str("b")}}})
+	if err != nil {
+		t.Fatalf("append err: %v", err)
+	}
+	if len(items) != 1 || app.Len() != 2 {
+		t.Errorf("want append to return new list got %v and %s", items, app)
+	}
+}
+
+func TestAssignCBOR(t *testing.T) {
+	want := myOrder{7, []myItem{{"a", "", 0}, {"b", "", 2}, {"c", "x", 3}},
+		map[string]string{"a": "b"}, &myPoint{X: 1, Y: 2}}
+	l, err := Adapt(want)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	b, err := MarshalCBOR(l)
+	if err != nil {
+		t.Fatalf("marshal err: %v", err)
+	}
+	res := myOrder{Items: []myItem{}}
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = AssignCBOR(bytes.NewReader(b), p)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("want %+v got %+v", want, res)
+	}
+	var big struct{ N uint64 }
+	p, err = NewProxy(&big)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = AssignCBOR(bytes.NewReader([]byte{0xa1, 0x61, 'n', 0x1b,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}), p)
+	if err != nil || big.N != 1<<64-1 {
+		t.Errorf("want max uint64 got %d %v", big.N, err)
+	}
+}
+
+func TestDecodeNumeral(t *testing.T) {
+	var res struct {
+		ID   int64
+		Uint uint64
+		Flt  float64
+		Sm   int32
+	}
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = Decode(strings.NewReader(`{id:9007199254740993 uint:9007199254740995 flt:0.1}`), p)
+	if err != nil {
+		t.Fatalf("decode err: %v", err)
+	}
+	if res.ID != 9007199254740993 || res.Uint != 9007199254740995 || res.Flt != 0.1 {
+		t.Errorf("want exact numbers got %+v", res)
+	}
+	for _, raw := range []string{`{id:9223372036854775808}`, `{id:9007199254740993.5}`, `{sm:9007199254740993}`} {
+		err = Decode(strings.NewReader(raw), p)
+		if err == nil {
+			t.Errorf("%s want error got %+v", raw, res)
+		}
+	}
+}
+
+func benchPayload(n int) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"name":"item %d","note":"some note text","qty":%d}`, i, i)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func BenchmarkDecodeRead(b *testing.B) {
+	raw := benchPayload(10000)
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		var res []myItem
+		l, err := Read(strings.NewReader(raw))
+		if err != nil {
+			b.Fatalf("read err: %v", err)
+		}
+		err = AssignTo(l, &res)
+		if err != nil {
+			b.Fatalf("assign err: %v", err)
+		}
+	}
+}
+
+func BenchmarkDecodeStream(b *testing.B) {
+	raw := benchPayload(10000)
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		var res []myItem
+		p, err := NewProxy(&res)
+		if err != nil {
+			b.Fatalf("proxy err: %v", err)
+		}
+		err = Decode(strings.NewReader(raw), p)
+		if err != nil {
+			b.Fatalf("decode err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package prx
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// LitMarshaler is implemented by go types that provide their own literal representation.
+// LitType is called on the zero value of the type and must return the type of all literals
+// returned by MarshalLit.
+type LitMarshaler interface {
+	LitType() typ.Type
+	MarshalLit() (lit.Lit, error)
+}
+
+// LitUnmarshaler is implemented by go types that can assign a literal to themselves.
+// The literal is converted to the type returned by LitType before it is passed to UnmarshalLit.
+type LitUnmarshaler interface {
+	UnmarshalLit(lit.Lit) error
+}
+
+var (
+	refLitM   = reflect.TypeOf((*LitMarshaler)(nil)).Elem()
+	refLitU   = reflect.TypeOf((*LitUnmarshaler)(nil)).Elem()
+	refTextM  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	refTextU  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	refJSONM  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	refJSONU  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	refValuer = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	refScan   = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// marshKind identifies the marshaler interfaces implemented by a go type.
+type marshKind int
+
+const (
+	marshNone marshKind = iota
+	marshLit
+	marshText
+	marshJSON
+	marshSQL
+)
+
+// marshalKind returns the marshaler interfaces implemented by the non-pointer go type t or its
+// pointer type, in the order of precedence: LitMarshaler, TextMarshaler, json.Marshaler and
+// driver.Valuer. Implementing only the unmarshaler counterpart is sufficient. Literals, types,
+// time values and types marked as span, enum or bits are never treated as marshalers. Structs
+// with json marshalers are reflected field by field.
+func marshalKind(t reflect.Type) marshKind {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return marshNone
+	}
+	if t.NumMethod() == 0 && reflect.PtrTo(t).NumMethod() == 0 {
+		return marshNone
+	}
+	pt := reflect.PtrTo(t)
+	if t == refTime || t == refType || pt.Implements(refLit) {
+		return marshNone
+	}
+	// span, enum and bits markers take precedence over marshalers
+	if isRef(t, refSecs) || isRef(t, refEnum) || isRef(t, refBits) {
+		return marshNone
+	}
+	switch {
+	case pt.Implements(refLitM) || pt.Implements(refLitU):
+		return marshLit
+	case pt.Implements(refTextM) || pt.Implements(refTextU):
+		return marshText
+	case (pt.Implements(refJSONM) || pt.Implements(refJSONU)) && t.Kind() != reflect.Struct:
+		return marshJSON
+	case pt.Implements(refValuer) || pt.Implements(refScan):
+		return marshSQL
+	}
+	return marshNone
+}
+
+// marshalPtr returns the marshaler kind of the go type t, or for optional fields of its element
+// type if t is a pointer type.
+func marshalPtr(t reflect.Type) marshKind {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return marshalKind(t)
+}
+
+// marshalType returns the default xelf type of go type t with marshaler kind mk or an error.
+// Text marshalers are strings, except for byte arrays that use the uuid type. Json and sql
+// marshalers use the any type. Struct fields can declare a more specific kind with a xelf tag.
+func marshalType(t reflect.Type, mk marshKind) (typ.Type, error) {
+	switch mk {
+	case marshLit:
+		m, ok := reflect.New(t).Interface().(LitMarshaler)
+		if !ok {
+			return typ.Void, cor.Errorf("%s does not implement LitMarshaler", t)
+		}
+		res := m.LitType()
+		if res.Kind == typ.KindVoid {
+			return typ.Void, cor.Errorf("%s has no literal type", t)
+		}
+		return res, nil
+	case marshText:
+		if isRef(t, refUUID) {
+			return typ.UUID, nil
+		}
+		return typ.Str, nil
+	}
+	return typ.Any, nil
+}
+
+// marshalLit returns the literal of type t for the marshaler pointer ptr of kind mk or an error.
+func marshalLit(ptr reflect.Value, t typ.Type, mk marshKind) (l lit.Lit, err error) {
+	v := ptr.Interface()
+	switch mk {
+	case marshLit:
+		m, ok := v.(LitMarshaler)
+		if !ok {
+			return nil, cor.Errorf("%s does not implement LitMarshaler", ptr.Type())
+		}
+		l, err = m.MarshalLit()
+	case marshText:
+		m, ok := v.(encoding.TextMarshaler)
+		if !ok {
+			return nil, cor.Errorf("%s does not implement TextMarshaler", ptr.Type())
+		}
+		var txt []byte
+		txt, err = m.MarshalText()
+		l = lit.Char(txt)
+	case marshJSON:
+		m, ok := v.(json.Marshaler)
+		if !ok {
+			return nil, cor.Errorf("%s does not implement json.Marshaler", ptr.Type())
+		}
+		var raw []byte
+		raw, err = m.MarshalJSON()
+		if err == nil {
+			l, err = lit.Read(bytes.NewReader(raw))
+		}
+	case marshSQL:
+		m, ok := v.(driver.Valuer)
+		if !ok {
+			return nil, cor.Errorf("%s does not implement driver.Valuer", ptr.Type())
+		}
+		var dv driver.Value
+		dv, err = m.Value()
+		if err == nil {
+			l, err = AdaptValue(reflect.ValueOf(dv))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		l = lit.Nil
+	}
+	if t.Kind == typ.KindAny {
+		return l, nil
+	}
+	return lit.Convert(l, t, 0)
+}
+
+// unmarshalLit assigns the literal l to the marshaler pointer ptr of kind mk or returns an error.
+func unmarshalLit(ptr reflect.Value, l lit.Lit, mk marshKind) error {
+	v := ptr.Interface()
+	switch mk {
+	case marshLit:
+		if m, ok := v.(LitUnmarshaler); ok {
+			return m.UnmarshalLit(l)
+		}
+	case marshText:
+		if m, ok := v.(encoding.TextUnmarshaler); ok {
+			switch c := lit.Deopt(l).(type) {
+			case nil, lit.Null:
+				return m.UnmarshalText(nil)
+			case lit.Character:
+				return m.UnmarshalText([]byte(c.Char()))
+			}
+			return cor.Errorf("%q not assignable to %s", l.Typ(), ptr.Type())
+		}
+	case marshJSON:
+		if m, ok := v.(json.Unmarshaler); ok {
+			if l == nil {
+				l = lit.Nil
+			}
+			raw, err := l.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			return m.UnmarshalJSON(raw)
+		}
+	case marshSQL:
+		if m, ok := v.(sql.Scanner); ok {
+			dv, err := driverValue(l)
+			if err != nil {
+				return err
+			}
+			return m.Scan(dv)
+		}
+	}
+	return cor.Errorf("%s cannot be assigned a literal", ptr.Type())
+}
+
+// driverValue returns a sql driver value for the literal l or an error.
+// Containers use their json representation.
+func driverValue(l lit.Lit) (interface{}, error) {
+	switch v := lit.Deopt(l).(type) {
+	case nil, lit.Null:
+		return nil, nil
+	case lit.Numeric:
+		switch n := v.Val().(type) {
+		case time.Duration:
+			return int64(n), nil
+		default:
+			return n, nil
+		}
+	case lit.Character:
+		switch c := v.Val().(type) {
+		case [16]byte, time.Duration:
+			return v.Char(), nil
+		default:
+			return c, nil
+		}
+	default:
+		return v.MarshalJSON()
+	}
+}
+
+// proxyMarsh is a proxy for go types implementing marshaler interfaces.
+// It is also used for optional marshaler fields, where the pointer value points to a pointer.
+type proxyMarsh struct {
+	proxy
+	kind marshKind
+}
+
+// newMarshProxy returns a marshaler proxy that implements lit.Numeric or lit.Character if the
+// type of p requires it.
+func newMarshProxy(p proxy, mk marshKind) lit.Proxy {
+	m := &proxyMarsh{p, mk}
+	switch p.typ.Kind & typ.KindAny {
+	case typ.KindNum:
+		return proxyMarshNum{m}
+	case typ.KindChar:
+		return proxyMarshChar{m}
+	}
+	return m
+}
+
+func (p *proxyMarsh) New() lit.Proxy { return newMarshProxy(p.new(), p.kind) }
+
+// target returns the marshaler pointer or an invalid value for nil pointers. Missing optional
+// values are allocated if alloc is true.
+func (p *proxyMarsh) target(alloc bool) reflect.Value {
+	v := p.val
+	if !v.IsValid() || v.IsNil() {
+		return reflect.Value{}
+	}
+	if e := v.Elem(); e.Kind() == reflect.Ptr {
+		if e.IsNil() {
+			if !alloc {
+				return reflect.Value{}
+			}
+			e.Set(reflect.New(e.Type().Elem()))
+		}
+		v = e
+	}
+	return v
+}
+
+// lit returns the marshaled literal of the current value.
+func (p *proxyMarsh) lit() (lit.Lit, error) {
+	v := p.target(false)
+	if !v.IsValid() {
+		return lit.Null(p.typ), nil
+	}
+	return marshalLit(v, p.typ, p.kind)
+}
+
+func (p *proxyMarsh) must() lit.Lit {
+	l, err := p.lit()
+	if err != nil {
+		return lit.Null(p.typ)
+	}
+	return l
+}
+
+func (p *proxyMarsh) Assign(l lit.Lit) error {
+	if l == nil || !p.val.IsValid() || p.val.IsNil() {
+		return cor.Errorf("%v not assignable to %s", l, p.typ)
+	}
+	if e := p.val.Elem(); e.Kind() == reflect.Ptr && l.IsZero() {
+		if _, ok := lit.Deopt(l).(lit.Null); ok || l.Typ().Kind&typ.KindOpt != 0 {
+			e.Set(reflect.Zero(e.Type()))
+			return nil
+		}
+	}
+	if p.typ.Kind != typ.KindAny {
+		var err error
+		l, err = lit.Convert(l, p.typ, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return unmarshalLit(p.target(true), l, p.kind)
+}
+
+func (p *proxyMarsh) IsZero() bool {
+	l, err := p.lit()
+	return err != nil || l.IsZero()
+}
+func (p *proxyMarsh) Some() lit.Lit {
+	if p.target(false).IsValid() {
+		return lit.Deopt(p.must())
+	}
+	return nil
+}
+func (p *proxyMarsh) String() string { return bfr.String(p) }
+func (p *proxyMarsh) MarshalJSON() ([]byte, error) {
+	l, err := p.lit()
+	if err != nil {
+		return nil, err
+	}
+	return l.MarshalJSON()
+}
+func (p *proxyMarsh) WriteBfr(b *bfr.Ctx) error {
+	l, err := p.lit()
+	if err != nil {
+		return err
+	}
+	return l.WriteBfr(b)
+}
+
+type proxyMarshNum struct{ *proxyMarsh }
+
+func (p proxyMarshNum) Num() float64 {
+	if n, ok := lit.Deopt(p.must()).(lit.Numeric); ok {
+		return n.Num()
+	}
+	return 0
+}
+func (p proxyMarshNum) Val() interface{} {
+	if n, ok := lit.Deopt(p.must()).(lit.Numeric); ok {
+		return n.Val()
+	}
+	return nil
+}
+
+type proxyMarshChar struct{ *proxyMarsh }
+
+func (p proxyMarshChar) Char() string {
+	if c, ok := lit.Deopt(p.must()).(lit.Character); ok {
+		return c.Char()
+	}
+	return ""
+}
+func (p proxyMarshChar) Val() interface{} {
+	if c, ok := lit.Deopt(p.must()).(lit.Character); ok {
+		return c.Val()
+	}
+	return nil
+}
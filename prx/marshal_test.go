@@ -0,0 +1,197 @@
+package prx
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+type mEmail struct{ User, Host string }
+
+func (e mEmail) MarshalText() ([]byte, error) {
+	if e.User == "" {
+		return nil, nil
+	}
+	return []byte(e.User + "@" + e.Host), nil
+}
+func (e *mEmail) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*e = mEmail{}
+		return nil
+	}
+	i := strings.IndexByte(string(b), '@')
+	if i < 0 {
+		return cor.Errorf("invalid email %q", b)
+	}
+	*e = mEmail{string(b[:i]), string(b[i+1:])}
+	return nil
+}
+
+type mPoint struct{ X, Y int64 }
+
+func (mPoint) LitType() typ.Type { return typ.List(typ.Int) }
+func (p mPoint) MarshalLit() (lit.Lit, error) {
+	return &lit.List{Elem: typ.Int, Data: []lit.Lit{lit.Int(p.X), lit.Int(p.Y)}}, nil
+}
+func (p *mPoint) UnmarshalLit(l lit.Lit) error {
+	v, ok := l.(lit.Indexer)
+	if !ok || v.Len() != 2 {
+		return cor.Errorf("invalid point %s", l)
+	}
+	x, _ := v.Idx(0)
+	y, _ := v.Idx(1)
+	p.X, p.Y = int64(x.(lit.Numeric).Num()), int64(y.(lit.Numeric).Num())
+	return nil
+}
+
+type mCents int64
+
+func (c mCents) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(c) / 100)
+}
+func (c *mCents) UnmarshalJSON(b []byte) error {
+	var f float64
+	err := json.Unmarshal(b, &f)
+	*c = mCents(f*100 + .5)
+	return err
+}
+
+type mContact struct {
+	Mail  mEmail
+	Alias *mEmail `json:"alias,omitempty"`
+	Pos   mPoint
+	Price mCents `xelf:",real"`
+	Extra mCents
+	Count sql.NullInt64 `xelf:"count?,int"`
+}
+
+func TestMarshal(t *testing.T) {
+	rt, err := Reflect(mContact{})
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	want := `<rec Mail:str Alias?:str? Pos:list|int Price:real Extra:any Count?:int>`
+	if got := rt.String(); got != want {
+		t.Errorf("want type %s got %s", want, got)
+	}
+	c := mContact{Mail: mEmail{"a", "b.c"}, Pos: mPoint{1, 2}, Price: 1250, Extra: 5,
+		Count: sql.NullInt64{Int64: 3, Valid: true}}
+	l, err := Adapt(c)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	wantStr := `{mail:'a@b.c' pos:[1 2] price:12.5 extra:0.05 count:3}`
+	if got := l.String(); got != wantStr {
+		t.Errorf("want adapted %s got %s", wantStr, got)
+	}
+	var res mContact
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = AssignTo(l, p)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	if !reflect.DeepEqual(res, c) {
+		t.Errorf("want %+v got %+v", c, res)
+	}
+	if got := p.String(); got != wantStr {
+		t.Errorf("want proxy %s got %s", wantStr, got)
+	}
+	k := p.(lit.Keyer)
+	el, err := k.Key("mail")
+	if err != nil {
+		t.Fatalf("key err: %v", err)
+	}
+	if ch, ok := el.(lit.Character); !ok || ch.Char() != "a@b.c" {
+		t.Errorf("want mail character got %T %s", el, el)
+	}
+	_, err = k.SetKey("alias", lit.Str("x@y.z"))
+	if err != nil {
+		t.Fatalf("set alias err: %v", err)
+	}
+	if res.Alias == nil || *res.Alias != (mEmail{"x", "y.z"}) {
+		t.Errorf("want alias set got %v", res.Alias)
+	}
+	_, err = k.SetKey("alias", lit.Null(typ.Str))
+	if err != nil || res.Alias != nil {
+		t.Errorf("want alias reset got %v %v", res.Alias, err)
+	}
+	_, err = k.SetKey("count", lit.Null(typ.Int))
+	if err != nil || res.Count.Valid {
+		t.Errorf("want count null got %v %v", res.Count, err)
+	}
+	_, err = k.SetKey("mail", lit.Str("invalid"))
+	if err == nil {
+		t.Errorf("want error for invalid mail")
+	}
+	var mail mEmail
+	mp, err := NewProxy(&mail)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	if mp.Typ() != typ.Str {
+		t.Errorf("want str proxy got %s", mp.Typ())
+	}
+	err = mp.Assign(lit.Str("d@e.f"))
+	if err != nil || mail != (mEmail{"d", "e.f"}) {
+		t.Errorf("want mail assigned got %v %v", mail, err)
+	}
+}
+
+type mState string
+
+func (mState) Enums() map[string]int64 { return map[string]int64{"open": 1, "done": 2} }
+func (s mState) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+func (s *mState) UnmarshalText(b []byte) error {
+	*s = mState(b)
+	return nil
+}
+
+type mGeo struct{ Lat, Lng float64 }
+
+func (g mGeo) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]float64{g.Lat, g.Lng})
+}
+
+type mPlace struct {
+	State mState
+	Geo   mGeo
+}
+
+func TestMarshalMarked(t *testing.T) {
+	rt, err := Reflect(mPlace{})
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	want := `<rec State:<enum prx.mState> Geo:<rec Lat:real Lng:real>>`
+	if got := rt.String(); got != want {
+		t.Errorf("want type %s got %s", want, got)
+	}
+	l, err := Adapt(mPlace{State: "done", Geo: mGeo{1.5, 2}})
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	wantStr := `{state:'done' geo:{lat:1.5 lng:2}}`
+	if got := l.String(); got != wantStr {
+		t.Errorf("want adapted %s got %s", wantStr, got)
+	}
+	var res mPlace
+	p, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = AssignTo(l, p)
+	if err != nil || res != (mPlace{State: "done", Geo: mGeo{1.5, 2}}) {
+		t.Errorf("want place assigned got %+v %v", res, err)
+	}
+}
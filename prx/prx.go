@@ -55,11 +55,38 @@ func NewProxy(ptr interface{}) (lit.Proxy, error) {
 //     bool, int64, float64, string, [16]byte, []byte, time.Time, List and *Dict
 // The numeric types int, int32, uint, uint32, float32 all list, dict and record types
 // use a proxy variant using reflection.
+//
+// Struct fields can use a xelf tag to specify the field key, optionality, a kind override and
+// a doc text. The tag takes precedence over the json tag and has the form:
+//     `xelf:"key,opt,enum,doc=the doc text"`
+// The key may be empty and can end in a question mark instead of using the opt option. The req
+// option marks a field as required even when the json tag uses omitempty. A kind override can
+// be one of int, bits or span for integers, enum for strings, or str and raw for strings and
+// byte slices. The doc text must be the last option and can be accessed with ReflectDocs.
+//
+// Types implementing LitMarshaler, encoding.TextMarshaler, json.Marshaler or driver.Valuer, or
+// their unmarshaler counterparts, use a proxy that calls these methods. Text marshalers use the
+// str type or any character kind declared by a xelf tag, byte arrays use uuid. Json and sql
+// marshalers use the any type or any primitive kind declared by a xelf tag.
+//
+// Interface types with variants registered with RegisterVariant use a proxy for tagged records.
+//
+// Proxies registered for a pointer type with Register take precedence over all other proxies.
 func ProxyValue(ptr reflect.Value) (lit.Proxy, error) {
 	if ptr.Kind() != reflect.Ptr {
 		return nil, ErrRequiresPtr
 	}
+	if p, ok := registered(ptr); ok {
+		return p, nil
+	}
 	et := ptr.Type().Elem()
+	if mk := marshalPtr(et); mk != marshNone {
+		c := cachedType(et)
+		if c.err != nil {
+			return nil, c.err
+		}
+		return newMarshProxy(proxy{c.typ, ptr}, mk), nil
+	}
 	// check for assignable primitives
 	switch et.Kind() {
 	case reflect.Bool:
@@ -114,13 +141,17 @@ func ProxyValue(ptr reflect.Value) (lit.Proxy, error) {
 		}
 	}
 	// generic proxy fallback
-	t, err := ReflectType(et)
-	if err != nil {
-		return nil, err
+	c := cachedType(et)
+	if c.err != nil {
+		return nil, c.err
 	}
+	t := c.typ
 	if t.Kind == typ.KindAny {
 		return &lit.AnyProxy{ptr, lit.Nil}, nil
 	}
+	if et.Kind() == reflect.Interface {
+		return &proxyVariant{proxy{t, ptr}}, nil
+	}
 	p := proxy{t, ptr}
 	switch t.Kind & typ.KindAny {
 	case typ.KindNum:
@@ -130,18 +161,35 @@ func ProxyValue(ptr reflect.Value) (lit.Proxy, error) {
 	case typ.KindKeyr:
 		return &proxyDict{p}, nil
 	case typ.KindCont:
-		if et.Kind() == reflect.Ptr {
-			et = et.Elem()
-		}
-		idx, err := fieldIndices(et, p.typ.Params)
-		if err != nil {
-			return nil, err
-		}
-		return &proxyRec{p, idx}, nil
+		return &proxyRec{p, c.idx}, nil
 	}
 	return nil, cor.Errorf("cannot proxy type %s as %s", ptr.Type(), t)
 }
 
+// fieldProxy returns a proxy for the field pointer ptr that respects the kind override of the
+// field type ft set by a xelf struct tag, or an error.
+func fieldProxy(ptr reflect.Value, ft typ.Type) (lit.Proxy, error) {
+	p, err := ProxyValue(ptr)
+	if err != nil || typ.Compare(p.Typ(), ft) >= typ.LvlCheck {
+		return p, err
+	}
+	et := ptr.Type().Elem()
+	if mk := marshalPtr(et); mk != marshNone {
+		return newMarshProxy(proxy{ft, ptr}, mk), nil
+	}
+	switch ft.Kind & typ.MaskRef {
+	case typ.KindSpan:
+		if v, ok := ptrRef(et, refSpan, ptr); ok {
+			return (*lit.Span)(v.Interface().(*time.Duration)), nil
+		}
+	case typ.KindInt, typ.KindBits:
+		return &proxyNum{proxy{ft, ptr}}, nil
+	case typ.KindStr, typ.KindRaw, typ.KindEnum:
+		return &proxyChar{proxy{ft, ptr}}, nil
+	}
+	return nil, cor.Errorf("cannot proxy type %s as %s", ptr.Type(), ft)
+}
+
 func ptrRef(et reflect.Type, ref reflect.Type, v reflect.Value) (reflect.Value, bool) {
 	if et == ref {
 		return v, true
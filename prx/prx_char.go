@@ -0,0 +1,78 @@
+package prx
+
+import (
+	"reflect"
+
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// proxyChar is a proxy for string and byte slice values with a str, raw or enum type.
+// It is used for struct fields with a kind override.
+type proxyChar struct{ proxy }
+
+func (p *proxyChar) New() lit.Proxy { return &proxyChar{p.new()} }
+
+func (p *proxyChar) Assign(l lit.Lit) error {
+	v := p.el()
+	if !v.IsValid() {
+		return cor.Errorf("%q not assignable to %q", l.Typ(), p.typ)
+	}
+	switch c := lit.Deopt(l).(type) {
+	case nil, lit.Null:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case lit.Character:
+		b, ok := c.Val().([]byte)
+		if !ok && p.typ.Kind&typ.MaskRef == typ.KindRaw {
+			r, err := lit.Convert(c, typ.Raw, 0)
+			if err != nil {
+				return err
+			}
+			b = []byte(r.(lit.Raw))
+			ok = true
+		}
+		if v.Kind() == reflect.String {
+			if ok {
+				v.SetString(string(b))
+			} else {
+				v.SetString(c.Char())
+			}
+		} else {
+			if !ok {
+				b = []byte(c.Char())
+			}
+			v.SetBytes(append(v.Bytes()[:0], b...))
+		}
+		return nil
+	}
+	return cor.Errorf("%q not assignable to %q", l.Typ(), p.typ)
+}
+
+// char returns the adapter literal for the current value.
+func (p *proxyChar) char() lit.Character {
+	var s string
+	if v := p.el(); v.IsValid() {
+		if v.Kind() == reflect.String {
+			s = v.String()
+		} else {
+			s = string(v.Bytes())
+		}
+	}
+	if p.typ.Kind&typ.MaskRef == typ.KindRaw {
+		return lit.Raw(s)
+	}
+	return lit.Str(s)
+}
+
+func (p *proxyChar) IsZero() bool {
+	v := p.el()
+	return !v.IsValid() || v.Len() == 0
+}
+func (p *proxyChar) Char() string                 { return p.char().Char() }
+func (p *proxyChar) Val() interface{}             { return p.char().Val() }
+func (p *proxyChar) String() string               { return p.char().String() }
+func (p *proxyChar) MarshalJSON() ([]byte, error) { return p.char().MarshalJSON() }
+func (p *proxyChar) WriteBfr(b *bfr.Ctx) error    { return p.char().WriteBfr(b) }
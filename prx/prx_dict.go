@@ -37,7 +37,7 @@ func (p *proxyDict) Assign(l lit.Lit) error {
 		if err != nil {
 			return err
 		}
-		v.SetMapIndex(reflect.ValueOf(k), fp.Elem())
+		v.SetMapIndex(mapKey(v, k), fp.Elem())
 		return nil
 	})
 }
@@ -60,7 +60,7 @@ func (p *proxyDict) Len() int {
 func (p *proxyDict) IsZero() bool { return p.Len() == 0 }
 func (p *proxyDict) Key(k string) (lit.Lit, error) {
 	if v, ok := p.elem(reflect.Map); ok {
-		return AdaptValue(v.MapIndex(reflect.ValueOf(k)))
+		return AdaptValue(v.MapIndex(mapKey(v, k)))
 	}
 	return lit.Null(p.typ.Elem()), nil
 }
@@ -75,12 +75,28 @@ func (p *proxyDict) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
 			v = reflect.MakeMap(v.Type())
 			p.val.Elem().Set(v)
 		}
-		v.SetMapIndex(reflect.ValueOf(k), ev.Elem())
+		v.SetMapIndex(mapKey(v, k), ev.Elem())
 		return p, nil
 	}
 	return p, cor.Errorf("not a map keyer")
 }
 
+func (p *proxyDict) Delete(k string) bool {
+	if v, ok := p.elem(reflect.Map); ok && !v.IsNil() {
+		kv := mapKey(v, k)
+		if v.MapIndex(kv).IsValid() {
+			v.SetMapIndex(kv, reflect.Value{})
+			return true
+		}
+	}
+	return false
+}
+
+// mapKey returns k as key value for the map v, that can have a named string key type.
+func mapKey(v reflect.Value, k string) reflect.Value {
+	return reflect.ValueOf(k).Convert(v.Type().Key())
+}
+
 func (p *proxyDict) Keys() (res []string) {
 	if v, ok := p.elem(reflect.Map); ok {
 		keys := v.MapKeys()
@@ -114,10 +130,14 @@ func (p *proxyDict) IterKey(it func(string, lit.Lit) error) error {
 
 func (p *proxyDict) String() string               { return bfr.String(p) }
 func (p *proxyDict) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
-func (p *proxyDict) WriteBfr(b *bfr.Ctx) error {
+func (p *proxyDict) WriteBfr(b *bfr.Ctx) error    { return WriteDict(b, p) }
+
+// WriteDict writes the elements of the dict d to b. It is used by generated proxies and writes the
+// same format as the reflection based proxies.
+func WriteDict(b *bfr.Ctx, d lit.Keyer) error {
 	b.WriteByte('{')
 	i := 0
-	err := p.IterKey(func(k string, el lit.Lit) error {
+	err := d.IterKey(func(k string, el lit.Lit) error {
 		if i > 0 {
 			writeSep(b)
 		}
@@ -162,3 +182,4 @@ func writeKey(b *bfr.Ctx, key string) (err error) {
 }
 
 var _ lit.Dictionary = &proxyDict{}
+var _ lit.Deleter = &proxyDict{}
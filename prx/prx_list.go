@@ -69,7 +69,7 @@ func (p *proxyList) Append(ls ...lit.Lit) (lit.Appender, error) {
 	}
 	res := *p
 	res.val = reflect.New(v.Type())
-	res.val.Set(v)
+	res.val.Elem().Set(v)
 	return &res, nil
 }
 
@@ -127,9 +127,13 @@ func (p *proxyList) IterIdx(it func(int, lit.Lit) error) error {
 
 func (p *proxyList) String() string               { return bfr.String(p) }
 func (p *proxyList) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
-func (p *proxyList) WriteBfr(b *bfr.Ctx) error {
+func (p *proxyList) WriteBfr(b *bfr.Ctx) error    { return WriteList(b, p) }
+
+// WriteList writes the elements of the list l to b. It is used by generated proxies and writes the
+// same format as the reflection based proxies.
+func WriteList(b *bfr.Ctx, l lit.Indexer) error {
 	b.WriteByte('[')
-	err := p.IterIdx(func(i int, el lit.Lit) error {
+	err := l.IterIdx(func(i int, el lit.Lit) error {
 		if i > 0 {
 			writeSep(b)
 		}
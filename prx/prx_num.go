@@ -28,11 +28,17 @@ func (p *proxyNum) Val() interface{} {
 
 func (p *proxyNum) Assign(l lit.Lit) error {
 	l = lit.Deopt(l)
+	if n, ok := l.(lit.Numeral); ok {
+		return p.assignNumeral(n)
+	}
 	if b, ok := l.(lit.Numeric); ok {
 		if v := p.el(); v.IsValid() {
 			switch v.Kind() {
 			case reflect.Int64, reflect.Int, reflect.Int32:
 				if e, ok := b.Val().(int64); ok {
+					if v.OverflowInt(e) {
+						return cor.Errorf("%d overflows %s", e, v.Type())
+					}
 					v.SetInt(e)
 					return nil
 				}
@@ -64,6 +70,49 @@ func (p *proxyNum) Assign(l lit.Lit) error {
 	return cor.Errorf("%q not assignable to %q", l.Typ(), p.typ)
 }
 
+func (p *proxyNum) assignNumeral(n lit.Numeral) error {
+	v := p.el()
+	switch v.Kind() {
+	case reflect.Int64, reflect.Int, reflect.Int32:
+		e, err := n.Int()
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(e) {
+			return cor.Errorf("numeral %s overflows %s", n, v.Type())
+		}
+		v.SetInt(e)
+		return nil
+	case reflect.Float64:
+		v.SetFloat(n.Num())
+		return nil
+	case reflect.Float32:
+		// parse again to avoid double rounding
+		e, err := strconv.ParseFloat(n.Raw, 32)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(e)
+		return nil
+	case reflect.Uint64, reflect.Uint, reflect.Uint32:
+		i, err := n.Int()
+		e := uint64(i)
+		if err != nil || i < 0 {
+			// only numerals beyond the int64 range are parsed again
+			e, err = strconv.ParseUint(n.Raw, 10, 64)
+			if err != nil {
+				return cor.Errorf("numeral %s has no exact uint representation", n)
+			}
+		}
+		if v.OverflowUint(e) {
+			return cor.Errorf("numeral %s overflows %s", n, v.Type())
+		}
+		v.SetUint(e)
+		return nil
+	}
+	return cor.Errorf("%q not assignable to %q", n.Typ(), p.typ)
+}
+
 func (p *proxyNum) IsZero() bool {
 	switch v := p.Val().(type) {
 	case int64:
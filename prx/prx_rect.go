@@ -6,6 +6,7 @@ import (
 	"github.com/mb0/xelf/bfr"
 	"github.com/mb0/xelf/cor"
 	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
 )
 
 type proxyRec struct {
@@ -21,7 +22,11 @@ func (p *proxyRec) Assign(l lit.Lit) error {
 	b, ok := lit.Deopt(l).(lit.Keyer)
 	if !ok || b.IsZero() { // a nil rec?
 		v := p.val.Elem()
-		v.Set(reflect.New(v.Type().Elem()))
+		if v.Kind() == reflect.Ptr {
+			v.Set(reflect.New(v.Type().Elem()))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
 		return nil
 	}
 	v, ok := p.elem(reflect.Struct)
@@ -38,7 +43,7 @@ func (p *proxyRec) Assign(l lit.Lit) error {
 			return cor.Error("no field index")
 		}
 		fv := v.FieldByIndex(idx)
-		fl, err := ProxyValue(fv.Addr())
+		fl, err := fieldProxy(fv.Addr(), p.typ.Params[i].Type)
 		if err != nil {
 			return err
 		}
@@ -72,7 +77,7 @@ func (p *proxyRec) Idx(i int) (lit.Lit, error) {
 		return nil, err
 	}
 	if v, ok := p.elem(reflect.Struct); ok {
-		res, err := ProxyValue(v.FieldByIndex(p.idx[i]).Addr())
+		res, err := fieldProxy(v.FieldByIndex(p.idx[i]).Addr(), f.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -87,7 +92,7 @@ func (p *proxyRec) Key(k string) (lit.Lit, error) {
 	}
 	if v, ok := p.elem(reflect.Struct); ok {
 		v = fieldByIndex(v, p.idx[i])
-		res, err := ProxyValue(v.Addr())
+		res, err := fieldProxy(v.Addr(), f.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -96,26 +101,33 @@ func (p *proxyRec) Key(k string) (lit.Lit, error) {
 	return lit.Null(f.Type), nil
 }
 func (p *proxyRec) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
-	_, err := p.typ.ParamByIdx(i)
+	f, err := p.typ.ParamByIdx(i)
 	if err != nil {
 		return p, err
 	}
 	if v, ok := p.elem(reflect.Struct); ok {
-		return p, AssignToValue(l, v.FieldByIndex(p.idx[i]).Addr())
+		return p, assignField(l, v.FieldByIndex(p.idx[i]).Addr(), f.Type)
 	}
 	return p, ErrNotStruct
 }
 func (p *proxyRec) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
-	_, i, err := p.typ.ParamByKey(k)
+	f, i, err := p.typ.ParamByKey(k)
 	if err != nil {
 		return p, err
 	}
 	if v, ok := p.elem(reflect.Struct); ok {
 		v = fieldByIndex(v, p.idx[i])
-		return p, AssignToValue(l, v.Addr())
+		return p, assignField(l, v.Addr(), f.Type)
 	}
 	return p, ErrNotStruct
 }
+func assignField(l lit.Lit, ptr reflect.Value, ft typ.Type) error {
+	fp, err := fieldProxy(ptr, ft)
+	if err != nil {
+		return err
+	}
+	return assignTo(l, fp)
+}
 func fieldByIndex(v reflect.Value, idx []int) reflect.Value {
 	for _, x := range idx {
 		if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
@@ -132,7 +144,7 @@ func (p *proxyRec) IterIdx(it func(int, lit.Lit) error) (err error) {
 	if v, ok := p.elem(reflect.Struct); ok && p.typ.Info != nil {
 		for i, f := range p.typ.Params {
 			var el lit.Lit
-			el, err = ProxyValue(v.FieldByIndex(p.idx[i]).Addr())
+			el, err = fieldProxy(v.FieldByIndex(p.idx[i]).Addr(), f.Type)
 			if err != nil {
 				return err
 			}
@@ -155,7 +167,7 @@ func (p *proxyRec) IterKey(it func(string, lit.Lit) error) (err error) {
 	if v, ok := p.elem(reflect.Struct); ok && p.typ.Info != nil {
 		for i, f := range p.typ.Params {
 			var el lit.Lit
-			el, err = ProxyValue(v.FieldByIndex(p.idx[i]).Addr())
+			el, err = fieldProxy(v.FieldByIndex(p.idx[i]).Addr(), f.Type)
 			if err != nil {
 				return err
 			}
@@ -177,26 +189,49 @@ func (p *proxyRec) IterKey(it func(string, lit.Lit) error) (err error) {
 func (p *proxyRec) String() string               { return bfr.String(p) }
 func (p *proxyRec) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
 func (p *proxyRec) WriteBfr(b *bfr.Ctx) error {
-	b.WriteByte('{')
-	n := 0
-	if v, ok := p.elem(reflect.Struct); ok && p.typ.Info != nil {
-		for i, f := range p.typ.Params {
-			v := fieldByIndex(v, p.idx[i])
-			el, err := ProxyValue(v.Addr())
-			if err != nil {
-				return err
-			}
-			if f.Opt() && el.IsZero() {
-				continue
-			}
-			if n++; n > 1 {
-				writeSep(b)
-			}
-			writeKey(b, f.Key())
-			err = writeLit(b, el)
+	v, ok := p.elem(reflect.Struct)
+	if !ok || p.typ.Info == nil {
+		return WriteFields(b, p.typ, nil)
+	}
+	return WriteFields(b, p.typ, func(i int) (lit.Proxy, error) {
+		return fieldProxy(fieldByIndex(v, p.idx[i]).Addr(), p.typ.Params[i].Type)
+	})
+}
+
+// WriteFields writes a record of type t to b, using field to access the proxy of field i.
+// A nil field function writes a record without fields. It is used by generated proxies and
+// writes the same format as the reflection based proxies.
+func WriteFields(b *bfr.Ctx, t typ.Type, field func(int) (lit.Proxy, error)) error {
+	var ps []typ.Param
+	var els []lit.Lit
+	if field != nil && t.Info != nil {
+		ps = t.Params
+		els = make([]lit.Lit, 0, len(ps))
+		for i := range ps {
+			el, err := field(i)
 			if err != nil {
 				return err
 			}
+			els = append(els, el)
+		}
+	}
+	if b.Tuple {
+		return lit.WriteTuple(b, ps, els)
+	}
+	b.WriteByte('{')
+	n := 0
+	for i, el := range els {
+		f := ps[i]
+		if f.Opt() && el.IsZero() {
+			continue
+		}
+		if n++; n > 1 {
+			writeSep(b)
+		}
+		writeKey(b, f.Key())
+		err := writeLit(b, el)
+		if err != nil {
+			return err
 		}
 	}
 	return b.WriteByte('}')
@@ -17,9 +17,10 @@ func Reflect(v interface{}) (typ.Type, error) {
 }
 
 // ReflectType returns the xelf type for the reflect type t or an error.
+// The results are cached and the returned type info must not be modified.
 func ReflectType(t reflect.Type) (res typ.Type, err error) {
-	nfos := make(infoMap)
-	return reflectType(t, nfos)
+	c := cachedType(t)
+	return c.typ, c.err
 }
 
 var (
@@ -34,9 +35,9 @@ var (
 	refTime = reflect.TypeOf(time.Time{})
 	refList = reflect.TypeOf((*lit.List)(nil))
 	refDict = reflect.TypeOf((*lit.Dict)(nil))
-	refSecs = reflect.TypeOf((*lit.MarkSpan)(nil))
-	refBits = reflect.TypeOf((*lit.MarkBits)(nil))
-	refEnum = reflect.TypeOf((*lit.MarkEnum)(nil))
+	refSecs = reflect.TypeOf((*lit.MarkSpan)(nil)).Elem()
+	refBits = reflect.TypeOf((*lit.MarkBits)(nil)).Elem()
+	refEnum = reflect.TypeOf((*lit.MarkEnum)(nil)).Elem()
 	refType = reflect.TypeOf(typ.Void)
 	refEl   = reflect.TypeOf((*interface {
 		WriteBfr(*bfr.Ctx) error
@@ -63,6 +64,13 @@ func reflectType(t reflect.Type, nfos infoMap) (res typ.Type, err error) {
 	if ptr = t.Kind() == reflect.Ptr; ptr {
 		t = t.Elem()
 	}
+	if mk := marshalKind(t); mk != marshNone {
+		res, err = marshalType(t, mk)
+		if err != nil || !ptr {
+			return res, err
+		}
+		return typ.Opt(res), nil
+	}
 	switch t.Kind() {
 	case reflect.Bool:
 		res = typ.Bool
@@ -93,7 +101,7 @@ func reflectType(t reflect.Type, nfos infoMap) (res typ.Type, err error) {
 	case reflect.String:
 		if isRef(t, refEnum) {
 			cs := reflect.Zero(t).Interface().(lit.MarkEnum).Enums()
-			res = typ.Type{typ.KindBits, getConstInfo(t, typ.Constants(cs))}
+			res = typ.Type{typ.KindEnum, getConstInfo(t, typ.Constants(cs))}
 			break
 		}
 		res = typ.Str
@@ -155,6 +163,9 @@ func reflectType(t reflect.Type, nfos infoMap) (res typ.Type, err error) {
 		}
 		res = typ.List(et)
 	case reflect.Interface:
+		if hasVariants(t) {
+			return Variant, nil
+		}
 		return typ.Any, nil
 	}
 	if res.IsZero() {
@@ -179,11 +190,17 @@ func reflectFields(t reflect.Type, nfos infoMap) (*typ.Info, [][]int, error) {
 	nfos[t] = nfo
 	fs := make([]typ.Param, 0, 16)
 	idx := make([][]int, 0, 16)
-	err := collectFields(t, nil, func(name, _ string, et reflect.Type, i []int) error {
+	err := collectFields(t, nil, func(name, _ string, et reflect.Type, tag fieldTag, i []int) error {
 		ft, err := reflectType(et, nfos)
 		if err != nil {
 			return err
 		}
+		if tag.Kind != typ.KindVoid {
+			ft, err = reflectKind(et, ft, tag.Kind)
+			if err != nil {
+				return cor.Errorf("field %s: %w", name, err)
+			}
+		}
 		fs = append(fs, typ.Param{name, ft})
 		var copy []int
 		idx = append(idx, append(copy, i...))
@@ -204,7 +221,7 @@ type fidx struct {
 
 func fieldIndices(t reflect.Type, fs []typ.Param) ([][]int, error) {
 	m := make(map[string]fidx, len(fs)+8)
-	err := collectFields(t, nil, func(name, key string, _ reflect.Type, idx []int) error {
+	err := collectFields(t, nil, func(name, key string, _ reflect.Type, _ fieldTag, idx []int) error {
 		var copy []int
 		m[key] = fidx{name, append(copy, idx...)}
 		return nil
@@ -223,7 +240,135 @@ func fieldIndices(t reflect.Type, fs []typ.Param) ([][]int, error) {
 	return res, nil
 }
 
-type fieldCollector = func(name, key string, t reflect.Type, idx []int) error
+type fieldCollector = func(name, key string, t reflect.Type, tag fieldTag, idx []int) error
+
+// fieldTag holds the kind override and doc text of a xelf struct tag.
+type fieldTag struct {
+	Kind typ.Kind
+	Doc  string
+}
+
+// parseTag parses a xelf struct tag and returns the key, the optionality and field tag or an error.
+// The optionality is positive for optional, negative for required and zero if not specified.
+func parseTag(tag string) (key string, opt int, ft fieldTag, err error) {
+	opts := strings.Split(tag, ",")
+	key = opts[0]
+	if n := len(key); n > 0 && key[n-1] == '?' {
+		key, opt = key[:n-1], 1
+	}
+	for i, o := range opts[1:] {
+		switch o {
+		case "":
+		case "opt":
+			opt = 1
+		case "req":
+			opt = -1
+		default:
+			if strings.HasPrefix(o, "doc=") { // the doc text may contain commas
+				ft.Doc = strings.Join(opts[i+1:], ",")[4:]
+				return key, opt, ft, nil
+			}
+			ft.Kind, err = typ.ParseKind(o)
+			if err != nil {
+				return key, opt, ft, cor.Errorf("invalid xelf tag option %q", o)
+			}
+		}
+	}
+	return key, opt, ft, nil
+}
+
+// reflectKind returns the type for go type t with the default type d and kind override k or an error.
+func reflectKind(t reflect.Type, d typ.Type, k typ.Kind) (res typ.Type, _ error) {
+	if d.Kind == k {
+		return d, nil
+	}
+	var nfo *typ.Info
+	if t.PkgPath() != "" {
+		nfo = getConstInfo(t, reflectConsts(t))
+	}
+	// text marshalers can use any character kind, json and sql marshalers any primitive kind
+	if mk := marshalPtr(t); mk == marshText && k&typ.KindChar != 0 ||
+		mk > marshText && k&typ.KindPrim != 0 {
+		res = typ.Type{Kind: k}
+		if k&typ.KindCtx != 0 {
+			res.Info = nfo
+		}
+		if t.Kind() == reflect.Ptr {
+			res = typ.Opt(res)
+		}
+		return res, nil
+	}
+	switch k {
+	case typ.KindInt:
+		if isInteger(t) {
+			res = typ.Int
+		}
+	case typ.KindSpan:
+		if t.Kind() == reflect.Int64 {
+			res = typ.Span
+		}
+	case typ.KindBits:
+		if isInteger(t) {
+			res = typ.Type{typ.KindBits, nfo}
+		}
+	case typ.KindEnum:
+		if t.Kind() == reflect.String {
+			res = typ.Type{typ.KindEnum, nfo}
+		}
+	case typ.KindStr:
+		if t.Kind() == reflect.String || isRef(t, refRaw) {
+			res = typ.Str
+		}
+	case typ.KindRaw:
+		if t.Kind() == reflect.String || isRef(t, refRaw) {
+			res = typ.Raw
+		}
+	}
+	if res.Kind == typ.KindVoid {
+		return typ.Void, cor.Errorf("cannot use kind %s for %s", k, t)
+	}
+	return res, nil
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func reflectConsts(t reflect.Type) []typ.Const {
+	if isRef(t, refEnum) {
+		return typ.Constants(reflect.Zero(t).Interface().(lit.MarkEnum).Enums())
+	}
+	if isRef(t, refBits) {
+		return typ.Constants(reflect.Zero(t).Interface().(lit.MarkBits).Bits())
+	}
+	return nil
+}
+
+// ReflectDocs returns the doc texts of xelf struct tags of the struct type t keyed by field key.
+// It returns nil if t is not a struct type or has no field docs.
+func ReflectDocs(t reflect.Type) map[string]string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var res map[string]string
+	collectFields(t, nil, func(_, key string, _ reflect.Type, tag fieldTag, _ []int) error {
+		if tag.Doc != "" {
+			if res == nil {
+				res = make(map[string]string)
+			}
+			res[key] = tag.Doc
+		}
+		return nil
+	})
+	return res
+}
 
 func collectFields(t reflect.Type, idx []int, col fieldCollector) error {
 	n := t.NumField()
@@ -235,13 +380,13 @@ func collectFields(t reflect.Type, idx []int, col fieldCollector) error {
 			}
 		}
 		var key string
-		var opt bool
+		var opt, skip bool
 		// check for a json struct tag first
 		tag := strings.Split(f.Tag.Get("json"), ",")
 		if len(tag) > 0 && tag[0] != "" {
 			key = tag[0]
 			if key == "-" { // skip ignored fields
-				continue
+				key, skip = "", true
 			}
 			// we found a key check if optional field
 			for _, t := range tag[1:] {
@@ -250,7 +395,28 @@ func collectFields(t reflect.Type, idx []int, col fieldCollector) error {
 				}
 			}
 		}
-		// collect embedded only if we have no key set by json tag explicitly
+		// a xelf struct tag takes precedence over the json tag
+		var ft fieldTag
+		if xt, ok := f.Tag.Lookup("xelf"); ok {
+			if xt == "-" {
+				continue
+			}
+			xkey, xopt, xft, err := parseTag(xt)
+			if err != nil {
+				return cor.Errorf("field %s: %w", f.Name, err)
+			}
+			if xkey != "" {
+				key = xkey
+			}
+			if xopt != 0 {
+				opt = xopt > 0
+			}
+			ft, skip = xft, false
+		}
+		if skip {
+			continue
+		}
+		// collect embedded only if we have no key set by a struct tag explicitly
 		if key == "" && f.Anonymous {
 			et := f.Type
 			if et.Kind() == reflect.Ptr {
@@ -273,7 +439,7 @@ func collectFields(t reflect.Type, idx []int, col fieldCollector) error {
 		if opt { // append a question mark to optional fields
 			name += "?"
 		}
-		err := col(name, key, f.Type, append(idx, i))
+		err := col(name, key, f.Type, ft, append(idx, i))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,73 @@
+package prx
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/lit"
+)
+
+type tagTask struct {
+	Name   string `json:"name" xelf:"title,doc=The task title, shown in lists"`
+	Status string `xelf:",enum"`
+	Data   []byte `xelf:"data,str"`
+	Hash   string `xelf:",raw"`
+	Limit  int64  `xelf:"limit?,span"`
+	Secret string `json:"-" xelf:"secret,opt"`
+	Skip   string `xelf:"-"`
+	Note   string `json:"note,omitempty" xelf:",req"`
+}
+
+func TestReflectTags(t *testing.T) {
+	rt, err := Reflect(tagTask{})
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	want := `<rec Title:str Status:<enum> Data:str Hash:raw Limit?:span Secret?:str Note:str>`
+	if got := rt.String(); got != want {
+		t.Errorf("want type %s got %s", want, got)
+	}
+	docs := ReflectDocs(reflect.TypeOf(tagTask{}))
+	if want := map[string]string{"title": "The task title, shown in lists"}; !reflect.DeepEqual(docs, want) {
+		t.Errorf("want docs %v got %v", want, docs)
+	}
+	var task tagTask
+	p, err := NewProxy(&task)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	l, err := lit.Read(strings.NewReader(`{title:'a' status:'open' data:'xyz' ` +
+		`hash:'\\x6869' limit:'1h' secret:'s' note:'n'}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	err = AssignTo(l, p)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	wantTask := tagTask{Name: "a", Status: "open", Data: []byte("xyz"), Hash: "hi",
+		Limit: int64(time.Hour), Secret: "s", Note: "n"}
+	if !reflect.DeepEqual(task, wantTask) {
+		t.Errorf("want %+v got %+v", wantTask, task)
+	}
+	wantStr := `{title:'a' status:'open' data:'xyz' hash:'hi' limit:'1:00:00' secret:'s' note:'n'}`
+	if got := p.String(); got != wantStr {
+		t.Errorf("want %s got %s", wantStr, got)
+	}
+	el, err := p.(lit.Keyer).Key("status")
+	if err != nil || el.Typ().String() != "<enum>" {
+		t.Errorf("want status enum got %v %v", el.Typ(), err)
+	}
+	b, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("json err: %v", err)
+	}
+	wantJSON := `{"name":"a","Status":"open","Data":"eHl6","Hash":"hi","Limit":3600000000000,` +
+		`"Skip":"","note":"n"}`
+	if got := string(b); got != wantJSON {
+		t.Errorf("want json %s got %s", wantJSON, got)
+	}
+}
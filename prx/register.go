@@ -0,0 +1,65 @@
+package prx
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// ProxyFunc returns a proxy for a pointer of the registered type.
+type ProxyFunc func(ptr interface{}) lit.Proxy
+
+var registry struct {
+	sync.RWMutex
+	m map[reflect.Type]ProxyFunc
+}
+
+// Register registers f to create proxies for the pointer type of ptr. The registered proxies are
+// used by NewProxy and ProxyValue instead of the reflection based proxies. It is usually called
+// from init functions of generated code.
+func Register(ptr interface{}, f ProxyFunc) {
+	t := reflect.TypeOf(ptr)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(ErrRequiresPtr)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.m == nil {
+		registry.m = make(map[reflect.Type]ProxyFunc)
+	}
+	registry.m[t] = f
+}
+
+func registered(ptr reflect.Value) (lit.Proxy, bool) {
+	registry.RLock()
+	f := registry.m[ptr.Type()]
+	registry.RUnlock()
+	if f == nil || !ptr.CanInterface() {
+		return nil, false
+	}
+	return f(ptr.Interface()), true
+}
+
+// FieldProxy returns a proxy for the struct field pointer ptr with field type ft or an error.
+// It respects kind overrides set by xelf struct tags and is used by generated proxies.
+func FieldProxy(ptr interface{}, ft typ.Type) (lit.Proxy, error) {
+	return fieldProxy(reflect.ValueOf(ptr), ft)
+}
+
+// ReflectFields returns the record type and field indices of the struct type t or an error.
+// It is used by code generators to match the reflection based proxies.
+func ReflectFields(t reflect.Type) (typ.Type, [][]int, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return typ.Void, nil, ErrNotStruct
+	}
+	c := cachedType(t)
+	if c.err != nil {
+		return typ.Void, nil, c.err
+	}
+	return c.typ, c.idx, nil
+}
@@ -0,0 +1,177 @@
+package prx
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+)
+
+// Tracked is a record proxy that records all writes by key path. Writes using SetKey, SetIdx or
+// Assign on the tracked proxy or on nested record proxies returned by Key and Idx are recorded.
+// The recorded writes can be retrieved as delta dict compatible with utl.ApplyDelta.
+type Tracked struct {
+	lit.Record
+	prx  lit.Proxy
+	tr   *tracker
+	path string
+}
+
+// Track returns a tracked record proxy for the interface value v or an error. The value is
+// either a record proxy or a pointer to a struct.
+func Track(v interface{}) (*Tracked, error) {
+	p, ok := v.(lit.Proxy)
+	if !ok {
+		var err error
+		p, err = ProxyValue(reflect.ValueOf(v))
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, ok := p.(lit.Record)
+	if !ok {
+		return nil, cor.Errorf("track expects record proxy got %s", p.Typ())
+	}
+	return &Tracked{r, p, &tracker{}, ""}, nil
+}
+
+// Delta returns the recorded writes as delta dict with key paths. A later write to the same path
+// or a parent path replaces earlier entries. Recorded values are copies taken at the time of the
+// write and are not affected by later changes of the tracked value.
+func (t *Tracked) Delta() *lit.Dict {
+	return &lit.Dict{List: append([]lit.Keyed(nil), t.tr.list...)}
+}
+
+// Reset clears all recorded writes.
+func (t *Tracked) Reset() { t.tr.list = nil }
+
+func (t *Tracked) New() lit.Proxy {
+	p := t.prx.New()
+	return &Tracked{p.(lit.Record), p, &tracker{}, ""}
+}
+func (t *Tracked) Ptr() interface{} { return t.prx.Ptr() }
+func (t *Tracked) Assign(l lit.Lit) error {
+	err := t.prx.Assign(untrack(l))
+	if err != nil {
+		return err
+	}
+	if t.path != "" {
+		return t.tr.set(t.path, untrack(l))
+	}
+	k, ok := lit.Deopt(l).(lit.Keyer)
+	if ok && !k.IsZero() {
+		return k.IterKey(func(key string, el lit.Lit) error {
+			return t.tr.set(key, untrack(el))
+		})
+	}
+	return t.Record.IterKey(func(key string, el lit.Lit) error {
+		return t.tr.set(key, el)
+	})
+}
+
+func (t *Tracked) Idx(i int) (lit.Lit, error) {
+	l, err := t.Record.Idx(i)
+	if err != nil {
+		return nil, err
+	}
+	return t.child(t.idxKey(i), l), nil
+}
+func (t *Tracked) Key(k string) (lit.Lit, error) {
+	l, err := t.Record.Key(k)
+	if err != nil {
+		return nil, err
+	}
+	return t.child(t.key(k), l), nil
+}
+func (t *Tracked) SetIdx(i int, l lit.Lit) (lit.Indexer, error) {
+	path := t.idxKey(i)
+	if t.own(path, l) {
+		return t, nil
+	}
+	_, err := t.Record.SetIdx(i, untrack(l))
+	if err != nil {
+		return t, err
+	}
+	return t, t.tr.set(path, untrack(l))
+}
+func (t *Tracked) SetKey(k string, l lit.Lit) (lit.Keyer, error) {
+	path := t.key(k)
+	if t.own(path, l) {
+		return t, nil
+	}
+	_, err := t.Record.SetKey(k, untrack(l))
+	if err != nil {
+		return t, err
+	}
+	return t, t.tr.set(path, untrack(l))
+}
+func (t *Tracked) IterIdx(it func(int, lit.Lit) error) error {
+	return t.Record.IterIdx(func(i int, el lit.Lit) error {
+		return it(i, t.child(t.idxKey(i), el))
+	})
+}
+func (t *Tracked) IterKey(it func(string, lit.Lit) error) error {
+	return t.Record.IterKey(func(k string, el lit.Lit) error {
+		return it(k, t.child(t.key(k), el))
+	})
+}
+
+// own returns whether l is the nested tracked proxy at path. Its writes are already recorded.
+// This is the case when setting paths, because lit.SetPath sets the modified child in its parent.
+func (t *Tracked) own(path string, l lit.Lit) bool {
+	c, ok := l.(*Tracked)
+	return ok && c.tr == t.tr && c.path == path
+}
+
+func (t *Tracked) child(path string, l lit.Lit) lit.Lit {
+	if r, ok := l.(lit.Record); ok {
+		if p, ok := l.(lit.Proxy); ok {
+			return &Tracked{r, p, t.tr, path}
+		}
+	}
+	return l
+}
+
+func (t *Tracked) idxKey(i int) string {
+	if ps := t.Typ().Params; i >= 0 && i < len(ps) {
+		return t.key(ps[i].Key())
+	}
+	return t.key(strconv.Itoa(i))
+}
+
+func (t *Tracked) key(k string) string {
+	if t.path == "" {
+		return k
+	}
+	return t.path + "." + k
+}
+
+func untrack(l lit.Lit) lit.Lit {
+	if c, ok := l.(*Tracked); ok {
+		return c.prx
+	}
+	return l
+}
+
+type tracker struct {
+	list []lit.Keyed
+}
+
+// set records a copy of l written to path and removes earlier writes to path or its children.
+func (tr *tracker) set(path string, l lit.Lit) error {
+	l, err := lit.Clone(l)
+	if err != nil {
+		return err
+	}
+	res := tr.list[:0]
+	for _, kv := range tr.list {
+		if kv.Key == path || strings.HasPrefix(kv.Key, path) && kv.Key[len(path)] == '.' {
+			continue
+		}
+		res = append(res, kv)
+	}
+	tr.list = append(res, lit.Keyed{path, l})
+	return nil
+}
@@ -0,0 +1,78 @@
+package prx
+
+import (
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+)
+
+type trackAddr struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type trackUser struct {
+	Name string    `json:"name"`
+	Age  int64     `json:"age"`
+	Addr trackAddr `json:"addr"`
+	Tags []string  `json:"tags"`
+}
+
+func TestTrack(t *testing.T) {
+	u := trackUser{Name: "a", Age: 3, Addr: trackAddr{"x", "1"}}
+	tr, err := Track(&u)
+	if err != nil {
+		t.Fatalf("track err: %v", err)
+	}
+	set := func(path string, l lit.Lit) {
+		p, err := lit.ReadPath(path)
+		if err != nil {
+			t.Fatalf("read path %s err: %v", path, err)
+		}
+		_, err = lit.SetPath(tr, p, l, true)
+		if err != nil {
+			t.Fatalf("set path %s err: %v", path, err)
+		}
+	}
+	set("age", lit.Int(4))
+	set("addr.city", lit.Str("y"))
+	_, err = tr.SetIdx(0, lit.Str("b"))
+	if err != nil {
+		t.Fatalf("set idx err: %v", err)
+	}
+	set("age", lit.Int(5))
+	want := `{'addr.city':'y' name:'b' age:5}`
+	if got := tr.Delta().String(); got != want {
+		t.Errorf("want delta %s got %s", want, got)
+	}
+	if u.Name != "b" || u.Age != 5 || u.Addr.City != "y" {
+		t.Errorf("want writes to go through got %+v", u)
+	}
+	set("addr", &lit.Dict{List: []lit.Keyed{{"zip", lit.Str("2")}}})
+	want = `{name:'b' age:5 addr:{zip:'2'}}`
+	if got := tr.Delta().String(); got != want {
+		t.Errorf("want delta %s got %s", want, got)
+	}
+	u.Addr.Zip = "3"
+	u.Name = "c"
+	if got := tr.Delta().String(); got != want {
+		t.Errorf("want delta unaffected by later changes %s got %s", want, got)
+	}
+	err = tr.Assign(lit.Null(tr.Typ()))
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	u.Name = "d"
+	want = `{name:'' age:0 addr:{city:'' zip:''} tags:[]}`
+	if got := tr.Delta().String(); got != want {
+		t.Errorf("want delta %s got %s", want, got)
+	}
+	tr.Reset()
+	if got := tr.Delta().String(); got != `{}` {
+		t.Errorf("want empty delta after reset got %s", got)
+	}
+	_, err = Track(&[]string{})
+	if err == nil {
+		t.Errorf("want error tracking non record")
+	}
+}
@@ -0,0 +1,214 @@
+package prx
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// Variant is the optional record type used for interface values with registered variants.
+// The type field holds the discriminator name and the val field the concrete value:
+//     {type:'circle' val:{radius:2}}
+// A nil interface value is represented as null.
+var Variant = typ.Opt(variantRec)
+
+var variantRec = typ.Rec([]typ.Param{{"Type", typ.Str}, {"Val", typ.Any}})
+
+var variants struct {
+	sync.RWMutex
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+// RegisterVariant registers the concrete go type of v with the discriminator name. Interface
+// types implemented by at least one registered variant reflect to the Variant record type, all
+// other interface types reflect to any. Pointer types must be registered if the interface is
+// implemented with pointer receivers. It panics if the name or type is already registered.
+//
+// Variants should be registered from init functions, because registering clears the type cache.
+func RegisterVariant(name string, v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil || name == "" {
+		panic(cor.Errorf("invalid variant %q for %T", name, v))
+	}
+	variants.Lock()
+	defer variants.Unlock()
+	if variants.names == nil {
+		variants.names = make(map[reflect.Type]string)
+		variants.types = make(map[string]reflect.Type)
+	}
+	if _, ok := variants.types[name]; ok {
+		panic(cor.Errorf("variant %q already registered", name))
+	}
+	if _, ok := variants.names[t]; ok {
+		panic(cor.Errorf("variant type %s already registered", t))
+	}
+	variants.names[t] = name
+	variants.types[name] = t
+	ResetCache()
+}
+
+// VariantName returns the discriminator name registered for go type t and whether it was found.
+func VariantName(t reflect.Type) (string, bool) {
+	variants.RLock()
+	defer variants.RUnlock()
+	n, ok := variants.names[t]
+	return n, ok
+}
+
+// VariantType returns the go type registered for the discriminator name or nil.
+func VariantType(name string) reflect.Type {
+	variants.RLock()
+	defer variants.RUnlock()
+	return variants.types[name]
+}
+
+// hasVariants returns whether any registered variant implements the interface type t.
+// The empty interface and literal interfaces are never considered.
+func hasVariants(t reflect.Type) bool {
+	if t.NumMethod() == 0 || t.Implements(refLit) {
+		return false
+	}
+	variants.RLock()
+	defer variants.RUnlock()
+	for vt := range variants.names {
+		if vt.Implements(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// adaptVariant returns a variant record for the non-nil interface value v or an error.
+func adaptVariant(v reflect.Value) (lit.Lit, error) {
+	e := v.Elem()
+	name, ok := VariantName(e.Type())
+	if !ok {
+		return nil, cor.Errorf("no variant registered for %s", e.Type())
+	}
+	val, err := AdaptValue(e)
+	if err != nil {
+		return nil, err
+	}
+	return variantLit(name, val)
+}
+
+func variantLit(name string, val lit.Lit) (*lit.Rec, error) {
+	res, err := lit.MakeRec(variantRec)
+	if err != nil {
+		return nil, err
+	}
+	_, err = res.SetKey("type", lit.Str(name))
+	if err != nil {
+		return nil, err
+	}
+	_, err = res.SetKey("val", val)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// proxyVariant is a proxy for interface values with registered variants.
+// Pointer variants are proxied, values of other variants are adapted.
+type proxyVariant struct{ proxy }
+
+func (p *proxyVariant) New() lit.Proxy { return &proxyVariant{p.new()} }
+
+// rec returns the variant record of the current value or null.
+func (p *proxyVariant) rec() (lit.Lit, error) {
+	v := p.el()
+	if !v.IsValid() || v.IsNil() {
+		return lit.Null(p.typ), nil
+	}
+	e := v.Elem()
+	if e.Kind() != reflect.Ptr || e.IsNil() {
+		return adaptVariant(v)
+	}
+	name, ok := VariantName(e.Type())
+	if !ok {
+		return nil, cor.Errorf("no variant registered for %s", e.Type())
+	}
+	val, err := ProxyValue(e)
+	if err != nil {
+		return nil, err
+	}
+	return variantLit(name, val)
+}
+
+func (p *proxyVariant) Assign(l lit.Lit) error {
+	v := p.el()
+	if !v.IsValid() {
+		return cor.Errorf("%v not assignable to %s", l, p.typ)
+	}
+	k, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok || k.IsZero() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	tl, err := k.Key("type")
+	if err != nil {
+		return err
+	}
+	c, ok := lit.Deopt(tl).(lit.Character)
+	if !ok {
+		return cor.Errorf("variant type must be a string got %s", tl)
+	}
+	vt := VariantType(c.Char())
+	if vt == nil {
+		return cor.Errorf("unknown variant %q", c.Char())
+	}
+	if !vt.Implements(v.Type()) {
+		return cor.Errorf("variant %q does not implement %s", c.Char(), v.Type())
+	}
+	val, err := k.Key("val")
+	if err != nil {
+		return err
+	}
+	nv := reflect.New(vt)
+	if vt.Kind() == reflect.Ptr {
+		nv.Elem().Set(reflect.New(vt.Elem()))
+		err = AssignToValue(lit.Deopt(val), nv.Elem())
+	} else {
+		err = AssignToValue(lit.Deopt(val), nv)
+	}
+	if err != nil {
+		return err
+	}
+	v.Set(nv.Elem())
+	return nil
+}
+
+func (p *proxyVariant) IsZero() bool {
+	v := p.el()
+	return !v.IsValid() || v.IsNil()
+}
+func (p *proxyVariant) Some() lit.Lit {
+	if p.IsZero() {
+		return nil
+	}
+	l, err := p.rec()
+	if err != nil {
+		return nil
+	}
+	return l
+}
+func (p *proxyVariant) String() string { return bfr.String(p) }
+func (p *proxyVariant) MarshalJSON() ([]byte, error) {
+	l, err := p.rec()
+	if err != nil {
+		return nil, err
+	}
+	return l.MarshalJSON()
+}
+func (p *proxyVariant) WriteBfr(b *bfr.Ctx) error {
+	l, err := p.rec()
+	if err != nil {
+		return err
+	}
+	return l.WriteBfr(b)
+}
@@ -0,0 +1,91 @@
+package prx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+)
+
+type vShape interface{ Area() float64 }
+
+type vCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c vCircle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type vRect struct {
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+func (r *vRect) Area() float64 { return r.W * r.H }
+
+type vDrawing struct {
+	Name   string   `json:"name"`
+	Main   vShape   `json:"main"`
+	Shapes []vShape `json:"shapes"`
+	Extra  interface{}
+}
+
+func init() {
+	RegisterVariant("circle", vCircle{})
+	RegisterVariant("rect", &vRect{})
+}
+
+func TestVariant(t *testing.T) {
+	rt, err := Reflect(vDrawing{})
+	if err != nil {
+		t.Fatalf("reflect err: %v", err)
+	}
+	want := `<rec Name:str Main:<rec? Type:str Val:any> Shapes:<list|rec? Type:str Val:any> Extra:any>`
+	if got := rt.String(); got != want {
+		t.Errorf("want type %s got %s", want, got)
+	}
+	d := vDrawing{Name: "a", Main: vCircle{1}, Shapes: []vShape{&vRect{2, 3}, nil}}
+	wantStr := `{name:'a' main:{type:'circle' val:{radius:1}} ` +
+		`shapes:[{type:'rect' val:{w:2 h:3}} null] extra:null}`
+	l, err := Adapt(d)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	if got := l.String(); got != wantStr {
+		t.Errorf("want adapted %s got %s", wantStr, got)
+	}
+	p, err := NewProxy(&d)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	if got := p.String(); got != wantStr {
+		t.Errorf("want proxy %s got %s", wantStr, got)
+	}
+	in, err := lit.Read(strings.NewReader(`{name:'b' main:{type:'rect' val:{w:4 h:5}} ` +
+		`shapes:[{type:'circle' val:{radius:2}}]}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	var res vDrawing
+	err = AssignTo(in, &res)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	wantRes := vDrawing{Name: "b", Main: &vRect{4, 5}, Shapes: []vShape{vCircle{2}}}
+	if !reflect.DeepEqual(res, wantRes) {
+		t.Errorf("want %+v got %+v", wantRes, res)
+	}
+	rp, err := NewProxy(&res)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	_, err = rp.(lit.Keyer).SetKey("main", lit.Null(Variant))
+	if err != nil || res.Main != nil {
+		t.Errorf("want main reset got %v %v", res.Main, err)
+	}
+	bad, _ := lit.Read(strings.NewReader(`{type:'square' val:{}}`))
+	_, err = rp.(lit.Keyer).SetKey("main", bad)
+	if err == nil || !strings.Contains(err.Error(), "unknown variant") {
+		t.Errorf("want unknown variant error got %v", err)
+	}
+}
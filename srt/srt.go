@@ -0,0 +1,131 @@
+// Package srt sorts literals by multiple keys using the total literal order of lit.Order.
+package srt
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+)
+
+// Key is a sort specification that selects a value by path and specifies the direction.
+// An empty path selects the element itself.
+type Key struct {
+	Path lit.Path
+	Desc bool
+}
+
+func (k Key) String() string {
+	if k.Desc {
+		return "-" + k.Path.String()
+	}
+	return k.Path.String()
+}
+
+// ParseKeys parses a list of sort keys separated by whitespace or commas and returns it
+// or an error. Each key is a select path optionally prefixed with '+' for ascending or '-' for
+// descending order, for example "-date name".
+func ParseKeys(s string) ([]Key, error) {
+	fs := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || cor.Space(r) })
+	res := make([]Key, 0, len(fs))
+	for _, f := range fs {
+		var k Key
+		switch f[0] {
+		case '-':
+			k.Desc = true
+			fallthrough
+		case '+':
+			f = f[1:]
+		}
+		if f != "" && f != "." {
+			p, err := lit.ReadPath(f)
+			if err != nil {
+				return nil, cor.Errorf("sort key %q: %w", f, err)
+			}
+			k.Path = p
+		}
+		res = append(res, k)
+	}
+	return res, nil
+}
+
+// Lits stably sorts the literals by the sort keys using the total order of lit.Order.
+// Values that cannot be selected are treated as null. Without keys the elements are compared.
+func Lits(ls []lit.Lit, keys ...Key) {
+	if len(keys) == 0 {
+		keys = []Key{{}}
+	}
+	vals := make([][]lit.Lit, len(ls))
+	for i, l := range ls {
+		vs := make([]lit.Lit, len(keys))
+		for j, k := range keys {
+			vs[j] = sortValue(l, k.Path)
+		}
+		vals[i] = vs
+	}
+	sort.Stable(sorter{ls, vals, keys})
+}
+
+// List stably sorts the elements of the indexer l in place by the sort keys or returns an error.
+func List(l lit.Indexer, keys ...Key) error {
+	ls := make([]lit.Lit, 0, l.Len())
+	err := l.IterIdx(func(i int, el lit.Lit) error {
+		ls = append(ls, el)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	Lits(ls, keys...)
+	if v, ok := l.(*lit.List); ok {
+		copy(v.Data, ls)
+		return nil
+	}
+	// proxy elements may share the underlying values with the list, so we clone them first
+	for i, el := range ls {
+		if ls[i], err = lit.Clone(el); err != nil {
+			return err
+		}
+	}
+	for i, el := range ls {
+		_, err = l.SetIdx(i, el)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortValue(l lit.Lit, p lit.Path) lit.Lit {
+	if len(p) == 0 {
+		return l
+	}
+	v, err := lit.SelectPath(l, p)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+type sorter struct {
+	ls   []lit.Lit
+	vals [][]lit.Lit
+	keys []Key
+}
+
+func (s sorter) Len() int { return len(s.ls) }
+func (s sorter) Swap(i, j int) {
+	s.ls[i], s.ls[j] = s.ls[j], s.ls[i]
+	s.vals[i], s.vals[j] = s.vals[j], s.vals[i]
+}
+func (s sorter) Less(i, j int) bool {
+	a, b := s.vals[i], s.vals[j]
+	for n, k := range s.keys {
+		c := lit.Order(a[n], b[n])
+		if c != 0 {
+			return c < 0 != k.Desc
+		}
+	}
+	return false
+}
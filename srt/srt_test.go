@@ -0,0 +1,68 @@
+package srt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+)
+
+type item struct {
+	Name string
+	Qty  int
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		raw  string
+		keys string
+		want string
+	}{
+		{`[3 1.5 null 'a' 2]`, ``, `[null 1.5 2 3 'a']`},
+		{`[3 1.5 null 'a' 2]`, `-`, `['a' 3 2 1.5 null]`},
+		{`[{n:'b' a:1} {n:'a' a:2} {n:'c' a:1}]`, `a -n`,
+			`[{n:'c' a:1} {n:'b' a:1} {n:'a' a:2}]`},
+		{`[{n:'b'} {n:'a' a:2} {n:'c' a:1}]`, `-a,n`,
+			`[{n:'a' a:2} {n:'c' a:1} {n:'b'}]`},
+	}
+	for _, test := range tests {
+		l, err := lit.Read(strings.NewReader(test.raw))
+		if err != nil {
+			t.Fatalf("read %s err: %v", test.raw, err)
+		}
+		keys, err := ParseKeys(test.keys)
+		if err != nil {
+			t.Fatalf("parse sort keys %q err: %v", test.keys, err)
+		}
+		err = List(l.(lit.Indexer), keys...)
+		if err != nil {
+			t.Errorf("sort %s err: %v", test.raw, err)
+			continue
+		}
+		if got := l.String(); got != test.want {
+			t.Errorf("sort %s by %q want %s got %s", test.raw, test.keys, test.want, got)
+		}
+	}
+}
+
+func TestListProxy(t *testing.T) {
+	items := []item{{"b", 2}, {"a", 2}, {"c", 1}}
+	l, err := prx.NewProxy(&items)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	keys, err := ParseKeys("-qty name")
+	if err != nil {
+		t.Fatalf("parse sort keys err: %v", err)
+	}
+	err = List(l.(lit.Indexer), keys...)
+	if err != nil {
+		t.Fatalf("sort err: %v", err)
+	}
+	want := []item{{"a", 2}, {"b", 2}, {"c", 1}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("sort want %v got %v", want, items)
+	}
+}
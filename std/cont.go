@@ -59,6 +59,11 @@ type litLener interface {
 	Len() int
 }
 
+// idxIterer is implemented by indexers and lazy sequences.
+type idxIterer interface {
+	IterIdx(func(int, lit.Lit) error) error
+}
+
 var lenSpec = core.add(SpecDX("<form len <@|alt cont str raw> int>",
 	func(x CallCtx) (exp.El, error) {
 		err := x.Layout.Eval(x.Prog, x.Env, typ.Void)
@@ -66,8 +71,16 @@ var lenSpec = core.add(SpecDX("<form len <@|alt cont str raw> int>",
 			return nil, err
 		}
 		fst := x.Arg(0).(*exp.Atom)
-		if v, ok := deopt(fst.Lit).(litLener); ok {
+		switch v := deopt(fst.Lit).(type) {
+		case litLener:
 			return &exp.Atom{lit.Int(v.Len()), x.Source()}, nil
+		case *lit.Seq:
+			var n int
+			err = v.IterIdx(func(int, lit.Lit) error { n++; return nil })
+			if err != nil {
+				return nil, err
+			}
+			return &exp.Atom{lit.Int(n), x.Source()}, nil
 		}
 		return nil, cor.Errorf("cannot call len on %s", fst.Typ())
 	}))
@@ -115,6 +128,33 @@ func nth(x CallCtx, cont *exp.Atom, pred exp.El, idx int) (_ exp.El, err error)
 		}
 	}
 	switch v := deopt(cont.Lit).(type) {
+	case *lit.Seq:
+		if idx < 0 { // we need all elements to index from the end
+			l, err := v.List()
+			if err != nil {
+				return nil, err
+			}
+			idx, err = checkIdx(idx, len(l.Data))
+			if err != nil {
+				return nil, err
+			}
+			return &exp.Atom{Lit: l.Data[idx]}, nil
+		}
+		var res lit.Lit
+		err = v.IterIdx(func(i int, el lit.Lit) error {
+			if i < idx {
+				return nil
+			}
+			res = el
+			return lit.BreakIter
+		})
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return nil, lit.ErrIdxBounds
+		}
+		return &exp.Atom{Lit: res}, nil
 	case lit.Indexer:
 		idx, err = checkIdx(idx, v.Len())
 		if err != nil {
@@ -295,6 +335,19 @@ func (r *fIter) filter(x CallCtx, cont *exp.Atom) (lit.Lit, error) {
 			return nil, err
 		}
 		return out, nil
+	case *lit.Seq:
+		if r.k > 0 {
+			return nil, cor.Errorf("iter key parameter for idxer %s", cont.Typ())
+		}
+		return lit.NewSeq(v.Elem, func(yield func(lit.Lit) error) error {
+			return v.IterIdx(func(idx int, el lit.Lit) error {
+				res, err := r.eval(x, el, idx, "")
+				if err != nil || res.IsZero() {
+					return err
+				}
+				return yield(el)
+			})
+		}), nil
 	}
 	return nil, cor.Errorf("filter requires idxer or keyer got %s", cont.Typ())
 }
@@ -339,6 +392,9 @@ var eachSpec = decl.add(SpecDX("<form range n:int list|int>",
 		return &exp.Atom{Lit: list, Src: x.Src}, nil
 	}))
 
+// filterSpec returns the elements of a container for which the predicate returns true.
+// Sequences are filtered lazily, the predicate is evaluated and its errors are returned only
+// when the resulting sequence is iterated, possibly after the program returned.
 var filterSpec = decl.add(SpecDX("<form filter cont|@1 <func @1 bool> @2>",
 	func(x CallCtx) (exp.El, error) {
 		err := x.Layout.Eval(x.Prog, x.Env, typ.Void)
@@ -357,6 +413,8 @@ var filterSpec = decl.add(SpecDX("<form filter cont|@1 <func @1 bool> @2>",
 		return &exp.Atom{res, x.Src}, nil
 	}))
 
+// mapSpec returns a container with the results of calling the function for each element.
+// Like filter it maps sequences lazily and returns evaluation errors when the result is iterated.
 var mapSpec = decl.add(SpecDX("<form map cont|@1 <func @1 @2> @3>",
 	func(x CallCtx) (exp.El, error) {
 		err := x.Layout.Eval(x.Prog, x.Env, typ.Void)
@@ -433,6 +491,20 @@ var mapSpec = decl.add(SpecDX("<form map cont|@1 <func @1 @2> @3>",
 				return nil, err
 			}
 			return &exp.Atom{out, x.Src}, nil
+		case *lit.Seq:
+			if iter.k > 0 {
+				return nil, cor.Errorf("iter key parameter for idxer %s", cont.Typ())
+			}
+			out := lit.NewSeq(rt.Elem(), func(yield func(lit.Lit) error) error {
+				return v.IterIdx(func(idx int, el lit.Lit) error {
+					res, err := iter.eval(x, el, idx, "")
+					if err != nil {
+						return err
+					}
+					return yield(res)
+				})
+			})
+			return &exp.Atom{out, x.Src}, nil
 		}
 		return nil, cor.Errorf("map requires idxer or keyer got %s", cont.Typ())
 	}))
@@ -464,7 +536,7 @@ var foldSpec = decl.add(SpecDX("<form fold cont|@1 @2 <func @2 @1 @2> @2>",
 				return nil, err
 			}
 			return acc, nil
-		case lit.Indexer:
+		case idxIterer:
 			if iter.k > 0 {
 				return nil, cor.Errorf("iter key parameter for idxer %s", cont.Typ())
 			}
@@ -495,6 +567,12 @@ var foldrSpec = decl.add(SpecDX("<form foldr cont|@1 @2 <func @2 @1 @2> @2>",
 		if err != nil {
 			return nil, err
 		}
+		if v, ok := deopt(cont.Lit).(*lit.Seq); ok {
+			cont.Lit, err = v.List()
+			if err != nil {
+				return nil, err
+			}
+		}
 		switch v := deopt(cont.Lit).(type) {
 		case lit.Keyer:
 			keys := v.Keys()
@@ -60,7 +60,11 @@ var catSpec = core.add(SpecRX("<form cat <@1|alt str raw idxr> plain:list @1>",
 			}
 			res = lit.Raw(b.Bytes())
 		default:
-			apd, ok := fst.Lit.(lit.Appender)
+			c, err := cloneArg(fst.Lit)
+			if err != nil {
+				return nil, err
+			}
+			apd, ok := c.(lit.Appender)
 			if !ok {
 				break
 			}
@@ -100,7 +104,11 @@ var apdSpec = core.add(SpecDX("<form apd @1|list plain:list @1>",
 			return x.Call, err
 		}
 		atm := x.Arg(0).(*exp.Atom)
-		apd, ok := atm.Lit.(lit.Appender)
+		c, err := cloneArg(atm.Lit)
+		if err != nil {
+			return nil, err
+		}
+		apd, ok := c.(lit.Appender)
 		if !ok {
 			return nil, cor.Errorf("cannot append to %T", x.Arg(0))
 		}
@@ -136,6 +144,11 @@ var setSpec = core.add(SpecDX("<form set @1|keyr plain?:list|keyr tags?:dict @1>
 		if x.Count() == 1 {
 			return fst, nil
 		}
+		c, err := cloneArg(res)
+		if err != nil {
+			return nil, err
+		}
+		res = c.(lit.Keyer)
 		decls := x.Tags(2)
 		for _, d := range decls {
 			el, ok := d.Arg().(*exp.Atom)
@@ -154,6 +167,15 @@ var setSpec = core.add(SpecDX("<form set @1|keyr plain?:list|keyr tags?:dict @1>
 		return a, nil
 	}))
 
+// cloneArg returns a copy of the argument literal l that might be shared or an error.
+// Proxies are copied with the same go type, so the result type does not change.
+func cloneArg(l lit.Lit) (lit.Lit, error) {
+	if p, ok := l.(lit.Proxy); ok {
+		return lit.CloneProxy(p)
+	}
+	return lit.Clone(l)
+}
+
 func catChar(b bfr.B, raw bool, fst lit.Lit, args []exp.El) error {
 	err := writeChar(b, fst)
 	if err != nil {
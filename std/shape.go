@@ -0,0 +1,115 @@
+package std
+
+import (
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/exp"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+/*
+Shape projection
+
+The shape form selects fields from a keyer literal into a new record, similar to a graphql selection.
+Each tag names a field of the resulting record. Naked tags select the field with the same key, tags
+with a char or str literal select the field at that path and all other tags are evaluated as
+expressions with the keyer literal in the data scope. The result type is inferred by the resolver
+from the selected field types.
+
+(with {id:1 user:{first:'Jane' last:'Doe'} tags:['a' 'b']} (and
+	(eq (shape . id; name:'user.first') (<rec id:num name:char> id:1 name:'Jane'))
+	(eq (shape . tags:(len .tags)) (<rec tags:int> tags:2))
+))
+
+Lists of keyers can be shaped using map with a function: (map . (fn (shape _ id;)))
+*/
+
+var shapeSpec = core.add(SpecRX("<form shape keyr tags; @>",
+	func(x CallCtx) (exp.El, error) {
+		el, err := x.Prog.Resl(x.Env, x.Arg(0), typ.Void)
+		if err != nil {
+			return x.Call, err
+		}
+		t := elResType(el)
+		env := &exp.DataScope{x.Env, exp.Def{Type: t}}
+		if a, ok := el.(*exp.Atom); ok {
+			env.Lit = a.Lit
+		}
+		tags := x.Tags(1)
+		fs := make([]typ.Param, 0, len(tags))
+		for _, d := range tags {
+			var ft typ.Type
+			if path, ok := shapePath(d); ok {
+				if env.Lit != nil {
+					var l lit.Lit
+					l, err = lit.Select(env.Lit, path)
+					if err == nil {
+						ft = l.Typ()
+					}
+				} else {
+					ft, err = typ.Select(t, path)
+				}
+				if err != nil {
+					return x.Call, cor.Errorf("shape %s: %w", d.Key(), err)
+				}
+			} else {
+				res, err := x.Prog.Resl(env, d.El, typ.Void)
+				if err != nil {
+					return x.Call, err
+				}
+				ft = elResType(res)
+			}
+			fs = append(fs, typ.Param{Name: d.Key(), Type: ft})
+		}
+		ps := x.Sig.Params
+		p := &ps[len(ps)-1]
+		p.Type = typ.Rec(fs)
+		return x.Call, nil
+	},
+	func(x CallCtx) (exp.El, error) {
+		el, err := x.Prog.Eval(x.Env, x.Arg(0), typ.Void)
+		if err != nil {
+			return x.Call, err
+		}
+		l := el.(*exp.Atom).Lit
+		env := &exp.DataScope{x.Env, exp.Def{Type: l.Typ(), Lit: l}}
+		tags := x.Tags(1)
+		list := make([]lit.Keyed, 0, len(tags))
+		for _, d := range tags {
+			var v lit.Lit
+			if path, ok := shapePath(d); ok {
+				v, err = lit.Select(l, path)
+				if err != nil {
+					return nil, cor.Errorf("shape %s: %w", d.Key(), err)
+				}
+			} else {
+				res, err := x.Prog.Eval(env, d.El, typ.Void)
+				if err != nil {
+					return x.Call, err
+				}
+				a, ok := res.(*exp.Atom)
+				if !ok {
+					return nil, cor.Errorf("shape %s: want literal got %s", d.Key(), res)
+				}
+				v = a.Lit
+			}
+			list = append(list, lit.Keyed{d.Key(), v})
+		}
+		return &exp.Atom{Lit: lit.RecFromKeyed(list), Src: x.Src}, nil
+	}))
+
+// shapePath returns the selection path for naked tags and tags with a char or str literal.
+func shapePath(d *exp.Tag) (string, bool) {
+	if d.El == nil {
+		return d.Key(), true
+	}
+	if a, ok := d.El.(*exp.Atom); ok {
+		switch a.Lit.Typ().Kind & typ.MaskElem {
+		case typ.KindChar, typ.KindStr:
+			if c, ok := a.Lit.(lit.Character); ok {
+				return c.Char(), true
+			}
+		}
+	}
+	return "", false
+}
@@ -21,7 +21,7 @@ var decl = make(formMap, 8)
 // Comparison forms:
 //    eq, ne, equal, in, ni, lt, le, gt, ge
 // Other forms:
-//    len, with, dyn, con, cat, apd, set
+//    len, with, dyn, con, cat, apd, set, shape
 func Core(sym string) *exp.Spec {
 	if f, ok := core[sym]; ok {
 		return f
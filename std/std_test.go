@@ -7,6 +7,7 @@ import (
 
 	"github.com/mb0/xelf/exp"
 	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
 	"github.com/mb0/xelf/typ"
 )
 
@@ -99,6 +100,9 @@ func TestStdResolveExec(t *testing.T) {
 		{`(gt 2 1 0)`, lit.True},
 		{`(gt 0 0 2)`, lit.False},
 		{`(gt 2 0 0)`, lit.False},
+		{`(gt 9007199254740993 5)`, lit.True},
+		{`(lt 5 9007199254740993)`, lit.True},
+		{`(gt 9007199254740993 9007199254740992)`, lit.True},
 		{`(le 0 1 2)`, lit.True},
 		{`(le 2 1 0)`, lit.False},
 		{`(le 0 0 2)`, lit.True},
@@ -209,6 +213,12 @@ func TestStdResolveExec(t *testing.T) {
 		// {`(let sum:(fn (fold _ 0 (fn (add _ .1)))) (sum [1 2 3]))`, lit.Int(6)},
 		{`(with 'test' .)`, lit.Char("test")},
 		{`(with (<rec a:int> [1]) .a)`, lit.Int(1)},
+		{`(shape {id:1 user:{first:'Jane'}} id; name:'user.first' n:(len .user))`,
+			lit.RecFromKeyed([]lit.Keyed{
+				{"id", lit.Num(1)},
+				{"name", lit.Char("Jane")},
+				{"n", lit.Int(1)},
+			})},
 		{`((fn (eq (add 1 1) 2)))`, lit.True},
 		{`(eq true (eq ['a'] ['a']))`, lit.True},
 		{`(with [1 2 3 4 5]
@@ -385,6 +395,8 @@ func TestStdResolve(t *testing.T) {
 		{`([0] 1)`, `(apd [0] 1)`, "list"},
 		{`(set {a:0} b:1)`, `(set {a:0} b:1)`, "dict"},
 		{`(with {a:0} .a)`, `(with {a:0} .a)`, "num"},
+		{`(shape {a:0 b:{c:'x'}} a; c:'b.c' n:(len .b))`,
+			`(shape {a:0 b:{c:'x'}} a; c:'b.c' n:(len .b))`, "<rec a:num c:char n:int>"},
 		{`(let a:0 a)`, `(let a:0 a)`, "num"},
 		{`(fn (add 1 _))`, `(fn (add 1 _))`, "<func num num>"},
 		{`(fn (add d _))`, `(fn (add d _))`, "<func num int>"},
@@ -431,3 +443,132 @@ func callType(el exp.El) typ.Type {
 	}
 	return t
 }
+
+func TestStdSeq(t *testing.T) {
+	var pulled int
+	seq := lit.NewSeq(typ.Int, func(yield func(lit.Lit) error) error {
+		for i := 1; i <= 10000; i++ {
+			pulled++
+			if err := yield(lit.Int(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	tests := []struct {
+		raw    string
+		want   string
+		pulled int
+	}{
+		{`(fst s)`, `1`, 1},
+		{`(fst (filter s (fn (gt _ 4))))`, `5`, 5},
+		{`(fst (map s (fn (mul _ 3))))`, `3`, 1},
+		{`(fst (map (filter s (fn (gt _ 10))) (fn (mul _ 3))))`, `33`, 11},
+		{`(nth (map (filter s (fn (gt _ 10))) (fn (add _ 1))) 2)`, `14`, 13},
+		{`(len s)`, `10000`, 10000},
+		{`(fold s 0 (fn (add _ .1)))`, `50005000`, 10000},
+	}
+	for _, test := range tests {
+		env := exp.NewScope(Std)
+		env.Def("s", &exp.Def{Type: seq.Typ(), Lit: seq})
+		x, err := exp.Read(strings.NewReader(test.raw))
+		if err != nil {
+			t.Errorf("%s parse err: %v", test.raw, err)
+			continue
+		}
+		pulled = 0
+		r, err := exp.Eval(env, x)
+		if err != nil {
+			t.Errorf("%s eval err: %v", test.raw, err)
+			continue
+		}
+		if got := r.String(); got != test.want {
+			t.Errorf("%s want %s got %s", test.raw, test.want, got)
+		}
+		if pulled != test.pulled {
+			t.Errorf("%s want %d pulled elements got %d", test.raw, test.pulled, pulled)
+		}
+	}
+	env := exp.NewScope(Std)
+	env.Def("s", &exp.Def{Type: seq.Typ(), Lit: seq})
+	x, _ := exp.Read(strings.NewReader(`(filter s (fn (lt _ 4)))`))
+	pulled = 0
+	r, err := exp.Eval(env, x)
+	if err != nil {
+		t.Fatalf("eval err: %v", err)
+	}
+	res, ok := r.(*exp.Atom).Lit.(*lit.Seq)
+	if !ok || pulled != 0 {
+		t.Fatalf("want lazy seq result got %T after %d pulled", r.(*exp.Atom).Lit, pulled)
+	}
+	if got := res.String(); got != `[1 2 3]` || pulled != 10000 {
+		t.Errorf("want forced [1 2 3] got %s after %d pulled", got, pulled)
+	}
+	// errors of lazy results are only returned when the sequence is iterated
+	for _, raw := range []string{`(map s (fn (div 1 (sub _ 3))))`, `(filter s (fn (div 1 (sub _ 3))))`} {
+		x, _ = exp.Read(strings.NewReader(raw))
+		r, err = exp.Eval(env, x)
+		if err != nil {
+			t.Fatalf("%s eval err: %v", raw, err)
+		}
+		_, err = r.(*exp.Atom).Lit.(*lit.Seq).List()
+		if err == nil || !strings.Contains(err.Error(), "zero devision") {
+			t.Errorf("%s want zero division error when forced got %v", raw, err)
+		}
+	}
+}
+
+type cloneUser struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestStdCloneArgs(t *testing.T) {
+	u := cloneUser{"a", []string{"x"}}
+	tags := []string{"x"}
+	list := &lit.List{Elem: typ.Str, Data: []lit.Lit{lit.Str("x")}}
+	tests := []struct {
+		raw  string
+		want string
+		ptr  interface{}
+	}{
+		{`(set u name:'b')`, `{name:'b' tags:['x']}`, &u},
+		{`(apd t 'y')`, `['x' 'y']`, &tags},
+		{`(cat t ['y'])`, `['x' 'y']`, &tags},
+		{`(apd l 'y')`, `['x' 'y']`, nil},
+		{`(cat l ['y'])`, `['x' 'y']`, nil},
+	}
+	for _, test := range tests {
+		env := exp.NewScope(Std)
+		for k, v := range map[string]interface{}{"u": &u, "t": &tags} {
+			p, err := prx.NewProxy(v)
+			if err != nil {
+				t.Fatalf("proxy err: %v", err)
+			}
+			env.Def(k, &exp.Def{Type: p.Typ(), Lit: p})
+		}
+		env.Def("l", &exp.Def{Type: list.Typ(), Lit: list})
+		x, err := exp.Read(strings.NewReader(test.raw))
+		if err != nil {
+			t.Fatalf("%s parse err: %v", test.raw, err)
+		}
+		r, err := exp.Eval(env, x)
+		if err != nil {
+			t.Errorf("%s eval err: %v", test.raw, err)
+			continue
+		}
+		res := r.(*exp.Atom).Lit
+		if got := res.String(); got != test.want {
+			t.Errorf("%s want %s got %s", test.raw, test.want, got)
+		}
+		if test.ptr != nil {
+			p, ok := res.(lit.Proxy)
+			if !ok || reflect.TypeOf(p.Ptr()) != reflect.TypeOf(test.ptr) || p.Ptr() == test.ptr {
+				t.Errorf("%s want new proxy of %T got %T", test.raw, test.ptr, res)
+			}
+		}
+		if u.Name != "a" || len(u.Tags) != 1 || len(tags) != 1 || len(list.Data) != 1 {
+			t.Errorf("%s want arguments unchanged got %v %v %s", test.raw, u, tags, list)
+		}
+	}
+}
@@ -8,23 +8,83 @@ import (
 )
 
 // PathSeg is one segment of a path. It consists of a dot or slash, followed by a key or index.
+// Special segments may instead hold a slice range, a wildcard or a predicate filter, and may be
+// marked as recursive descent.
 type PathSeg struct {
 	Key string
 	Idx int
 	Sel bool
+	// Desc marks a recursive descent segment, written with an additional dot.
+	Desc bool
+	// Star marks a wildcard segment selecting all elements.
+	Star bool
+	// Slice marks an index range from Idx to the exclusive End index or to the last element if
+	// Open is true.
+	Slice bool
+	End   int
+	Open  bool
+	// Pred holds the predicate of a filter segment.
+	Pred *PathPred
 }
 
+// Multi returns whether the segment can select more than one element.
+// The remaining path is applied to each element of a multi segment result.
+func (s PathSeg) Multi() bool { return s.Desc || s.Star || s.Slice || s.Pred != nil }
+
 func (s PathSeg) String() string {
-	if s.Key != "" {
-		return s.Key
+	var b strings.Builder
+	if s.Desc {
+		b.WriteByte('.')
+	}
+	switch {
+	case s.Star:
+		b.WriteByte('*')
+	case s.Pred != nil:
+		b.WriteString(s.Pred.String())
+	case s.Slice:
+		if s.Idx != 0 {
+			b.WriteString(strconv.Itoa(s.Idx))
+		}
+		b.WriteByte(':')
+		if !s.Open {
+			b.WriteString(strconv.Itoa(s.End))
+		}
+	case s.Key != "":
+		b.WriteString(s.Key)
+	default:
+		b.WriteString(strconv.Itoa(s.Idx))
+	}
+	return b.String()
+}
+
+// PathPred is a predicate filter that compares the value at a relative path with a literal.
+// The op is empty for predicates that only check for a non-zero value. Raw holds the literal text,
+// which is parsed by the literal package. Val holds the parsed literal if the path was read by the
+// literal package.
+type PathPred struct {
+	Path Path
+	Op   string
+	Raw  string
+	Val  interface{}
+}
+
+func (p *PathPred) String() string {
+	if p.Op == "" {
+		return "[" + p.Path.String() + "]"
 	}
-	return strconv.Itoa(s.Idx)
+	return "[" + p.Path.String() + " " + p.Op + " " + p.Raw + "]"
 }
 
 // Path consists of non-empty segments separated by dots '.' or slashes '/'. Segments starting with
 // a digit or minus sign are idx segments that try to select into an idxer literal,
 // otherwise the segment represents a key used to select into a keyer literal.
 // Segments starting with a slash signify a selection from a idxer literal.
+//
+// Additionally a segment can be a slice 'items/1:3', a wildcard 'items.*' or a predicate filter
+// in square brackets 'items/[price > 10]'. A segment preceded by an additional dot 'items..name',
+// or a dot at the start of the path '.name', selects recursively from all descendants.
+// Predicates compare the value at a relative path using one of the operators = != < <= > >=
+// with a literal, or check for a non-zero value if the operator and literal is omitted '[active]'.
 type Path []PathSeg
 
 func (p Path) String() string {
@@ -48,42 +108,132 @@ func ReadPath(path string) (res Path, err error) {
 		return nil, nil
 	}
 	res = make(Path, 0, len(path)>>2)
-	var last int
-	var sel bool
-	for i, r := range path {
-		switch r {
-		case '.', '/':
-			res, err = addSeg(res, path[last:i], sel)
-			if err != nil {
-				return nil, err
+	for i := 0; i < len(path); {
+		var sel, desc bool
+		if i > 0 {
+			if c := path[i]; c != '.' && c != '/' {
+				return nil, cor.Errorf("expect path separator got %q", c)
+			}
+			sel = path[i] == '/'
+			i++
+			if i == len(path) {
+				break
 			}
-			sel = r == '/'
-			last = i + 1
 		}
-	}
-	if len(path) > last {
-		res, err = addSeg(res, path[last:], sel)
+		if path[i] == '.' {
+			desc = true
+			i++
+		}
+		end, err := segEnd(path, i)
+		if err != nil {
+			return nil, err
+		}
+		seg, err := readSeg(path[i:end], sel)
 		if err != nil {
 			return nil, err
 		}
+		seg.Desc = desc
+		res = append(res, seg)
+		i = end
 	}
 	return res, nil
 }
 
-func addSeg(p Path, s string, sel bool) (Path, error) {
+func segEnd(path string, i int) (int, error) {
+	if i < len(path) && path[i] == '[' {
+		var quote byte
+		for j := i + 1; j < len(path); j++ {
+			c := path[j]
+			switch {
+			case quote != 0:
+				if c == '\\' && quote != '`' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+			case c == ']':
+				return j + 1, nil
+			}
+		}
+		return 0, cor.Errorf("unclosed predicate in path %q", path)
+	}
+	for j := i; j < len(path); j++ {
+		if c := path[j]; c == '.' || c == '/' {
+			return j, nil
+		}
+	}
+	return len(path), nil
+}
+
+func readSeg(s string, sel bool) (PathSeg, error) {
 	if s == "" {
-		return nil, cor.Error("empty segment")
+		return PathSeg{}, cor.Error("empty segment")
 	}
-	if c := s[0]; c == '-' || c >= '0' && c <= '9' {
+	switch c := s[0]; {
+	case c == '[':
+		p, err := readPred(s[1 : len(s)-1])
+		return PathSeg{Pred: p, Sel: sel}, err
+	case s == "*":
+		return PathSeg{Star: true, Sel: sel}, nil
+	case c == '-' || c == ':' || c >= '0' && c <= '9':
+		if idx := strings.IndexByte(s, ':'); idx >= 0 {
+			start, err := atoiOpt(s[:idx])
+			if err != nil {
+				return PathSeg{}, err
+			}
+			seg := PathSeg{Idx: start, Slice: true, Sel: sel, Open: idx+1 == len(s)}
+			seg.End, err = atoiOpt(s[idx+1:])
+			return seg, err
+		}
 		i, err := strconv.Atoi(s)
 		if err != nil {
-			return nil, err
+			return PathSeg{}, err
+		}
+		return PathSeg{Idx: i, Sel: sel}, nil
+	}
+	return PathSeg{Key: s, Sel: sel}, nil
+}
+
+func atoiOpt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+var predOps = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+func readPred(s string) (*PathPred, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexAny(s, "=!<> \t\n")
+	if end < 0 {
+		end = len(s)
+	}
+	p, err := ReadPath(s[:end])
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, cor.Errorf("empty predicate path in %q", s)
+	}
+	res := &PathPred{Path: p}
+	rest := strings.TrimSpace(s[end:])
+	if rest == "" {
+		return res, nil
+	}
+	for _, op := range predOps {
+		if strings.HasPrefix(rest, op) {
+			res.Op = op
+			res.Raw = strings.TrimSpace(rest[len(op):])
+			if res.Raw == "" {
+				break
+			}
+			return res, nil
 		}
-		p = append(p, PathSeg{Idx: i, Sel: sel})
-	} else {
-		p = append(p, PathSeg{Key: s, Sel: sel})
 	}
-	return p, nil
+	return nil, cor.Errorf("invalid predicate %q", s)
 }
 
 // Select reads path and returns the selected type from t or an error.
@@ -96,8 +246,32 @@ func Select(t Type, path string) (Type, error) {
 }
 
 // SelectPath returns the selected type from t or an error.
+// Select and multi segments result in a list of the type selected by the remaining path. The path
+// 'items/name' selects the name of each element in items, just like the literal selection.
 func SelectPath(t Type, p Path) (r Type, err error) {
-	for _, s := range p {
+	for i, s := range p {
+		if s.Multi() || s.Sel {
+			var el Type
+			rest := p[i:]
+			if s.Multi() {
+				el, err = selectMulti(t, s)
+				rest = rest[1:]
+			} else {
+				el, err = selectElem(t)
+			}
+			if err != nil {
+				return Void, err
+			}
+			// the remaining path is applied to each element and not selected again
+			if len(rest) > 0 && rest[0].Sel && !rest[0].Multi() {
+				rest = append(Path{{Key: rest[0].Key, Idx: rest[0].Idx}}, rest[1:]...)
+			}
+			r, err = SelectPath(el, rest)
+			if err != nil {
+				return Void, err
+			}
+			return List(r), nil
+		}
 		if s.Key != "" {
 			r, err = SelectKey(t, s.Key)
 		} else {
@@ -111,6 +285,37 @@ func SelectPath(t Type, p Path) (r Type, err error) {
 	return t, nil
 }
 
+func selectElem(t Type) (Type, error) {
+	switch t.Kind & MaskElem {
+	case KindAny, KindIdxr, KindKeyr, KindRec:
+		return Any, nil
+	case KindList, KindDict:
+		return t.Elem(), nil
+	}
+	return Void, cor.Errorf("select segment expects container type got %s", t)
+}
+
+func selectMulti(t Type, s PathSeg) (Type, error) {
+	if s.Desc {
+		// descendants can be of any type
+		return Any, nil
+	}
+	if s.Slice && t.Kind&MaskElem == KindDict {
+		return Void, cor.Errorf("slice segment expects idxer type got %s", t)
+	}
+	el, err := selectElem(t)
+	if err != nil {
+		return Void, err
+	}
+	if s.Pred != nil && el != Any {
+		_, err = SelectPath(el, s.Pred.Path)
+		if err != nil {
+			return Void, cor.Errorf("predicate %s: %w", s.Pred, err)
+		}
+	}
+	return el, nil
+}
+
 func SelectKey(t Type, key string) (Type, error) {
 	switch t.Kind & MaskElem {
 	case KindAny, KindKeyr:
@@ -0,0 +1,52 @@
+package typ
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	rt, err := Read(strings.NewReader(
+		`<rec items:<list|rec name:str price:int tags:list|str> meta:dict|str>`))
+	if err != nil {
+		t.Fatalf("read type error: %v", err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"items.1.name", "str"},
+		{"items/name", "list|str"},
+		{"items/1:3/price", "list|int"},
+		{"items/:-1", "<list|rec name:str price:int tags:list|str>"},
+		{"items.0.tags/0:1", "list|str"},
+		{"items.*.price", "list|int"},
+		{"items.*.tags", "list|list|str"},
+		{"meta.*", "list|str"},
+		{"items/[price > 10]", "<list|rec name:str price:int tags:list|str>"},
+		{"items/[tags]/name", "list|str"},
+		{".name", "list"},
+		{"items..price", "list"},
+	}
+	for _, test := range tests {
+		res, err := Select(rt, test.path)
+		if err != nil {
+			t.Errorf("select %s error: %v", test.path, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("select %s want %s got %s", test.path, test.want, got)
+		}
+	}
+	errs := []string{
+		"items/[cost > 1]",
+		"items.*.cost",
+		"meta/1:2",
+		"items.0.name.*",
+	}
+	for _, path := range errs {
+		if res, err := Select(rt, path); err == nil {
+			t.Errorf("select %s want error got %s", path, res)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package utl
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// csvCol is a flattened record field with its select path and type.
+type csvCol struct {
+	Key  string
+	Path lit.Path
+	Type typ.Type
+	Opt  bool
+}
+
+// CSVReader reads records of a record type from a CSV stream with a header row.
+// The underlying csv reader can be configured before the first read, for example to read TSV.
+type CSVReader struct {
+	R    *csv.Reader
+	Type typ.Type
+	cols []*csvCol
+	line int
+}
+
+// NewCSVReader returns a new reader for r and the record or list of records type t or an error.
+func NewCSVReader(r io.Reader, t typ.Type) (*CSVReader, error) {
+	rt, err := csvRecType(t)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVReader{R: csv.NewReader(r), Type: rt}, nil
+}
+
+// NewTSVReader returns a new tab-separated reader for r and type t or an error.
+func NewTSVReader(r io.Reader, t typ.Type) (*CSVReader, error) {
+	cr, err := NewCSVReader(r, t)
+	if err != nil {
+		return nil, err
+	}
+	cr.R.Comma = '\t'
+	cr.R.LazyQuotes = true
+	return cr, nil
+}
+
+// Read returns the next record or an error. It returns io.EOF after the last record.
+//
+// The first call reads the header row and maps the column names to field keys. Nested fields use
+// dotted keys. Unknown columns and missing columns for required fields result in an error.
+// Cells are converted to the field type, empty cells are left as zero for optional fields.
+// Rows with fewer cells, allowed by setting R.FieldsPerRecord to -1, are read as empty cells.
+func (r *CSVReader) Read() (*lit.Rec, error) {
+	if r.cols == nil {
+		err := r.readHeader()
+		if err != nil {
+			return nil, err
+		}
+	}
+	row, err := r.R.Read()
+	if err != nil {
+		return nil, err
+	}
+	res, err := lit.MakeRec(r.Type)
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	if len(row) > len(r.cols) {
+		return nil, cor.Errorf("csv row %d column %d: want %d columns", r.line, len(r.cols)+1,
+			len(r.cols))
+	}
+	for i, c := range r.cols {
+		var cell string
+		if i < len(row) {
+			cell = row[i]
+		}
+		if cell == "" && c.Opt {
+			continue
+		}
+		el, err := csvCell(cell, c.Type)
+		if err != nil {
+			return nil, cor.Errorf("csv row %d column %s: %w", r.line, c.Key, err)
+		}
+		_, err = lit.SetPath(res, c.Path, el, true)
+		if err != nil {
+			return nil, cor.Errorf("csv row %d column %s: %w", r.line, c.Key, err)
+		}
+	}
+	return res, nil
+}
+
+// ReadAll reads all records, appends them to a and returns the resulting appender or an error.
+// The appender can be a generic list or a proxy, for example of a slice of structs. Appending
+// returns a new appender, that can be assigned to the original proxy.
+func (r *CSVReader) ReadAll(a lit.Appender) (lit.Appender, error) {
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return a, err
+		}
+		el, err := a.Element()
+		if err != nil {
+			return a, err
+		}
+		err = el.Assign(rec)
+		if err != nil {
+			return a, err
+		}
+		a, err = a.Append(el)
+		if err != nil {
+			return a, err
+		}
+	}
+}
+
+func (r *CSVReader) readHeader() error {
+	head, err := r.R.Read()
+	if err != nil {
+		if err == io.EOF {
+			return cor.Errorf("csv header missing")
+		}
+		return err
+	}
+	all := csvCols(nil, nil, "", r.Type, false)
+	r.cols = make([]*csvCol, 0, len(head))
+	seen := make(map[string]bool, len(head))
+	for _, h := range head {
+		key := cor.Keyed(h)
+		c := findCol(all, key)
+		if c == nil {
+			return cor.Errorf("csv unknown column %q", h)
+		}
+		seen[key] = true
+		r.cols = append(r.cols, c)
+	}
+	for _, c := range all {
+		if !c.Opt && !seen[c.Key] {
+			return cor.Errorf("csv missing column %q", c.Key)
+		}
+	}
+	return nil
+}
+
+// CSVWriter writes records of a record type as CSV with a header row.
+// Nested record fields are flattened and use dotted keys in the header.
+type CSVWriter struct {
+	W    *csv.Writer
+	Type typ.Type
+	cols []*csvCol
+	row  []string
+}
+
+// NewCSVWriter returns a new writer for w and the record or list of records type t or an error.
+func NewCSVWriter(w io.Writer, t typ.Type) (*CSVWriter, error) {
+	rt, err := csvRecType(t)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVWriter{W: csv.NewWriter(w), Type: rt}, nil
+}
+
+// NewTSVWriter returns a new tab-separated writer for w and type t or an error.
+func NewTSVWriter(w io.Writer, t typ.Type) (*CSVWriter, error) {
+	cw, err := NewCSVWriter(w, t)
+	if err != nil {
+		return nil, err
+	}
+	cw.W.Comma = '\t'
+	return cw, nil
+}
+
+// Write writes the record l as row and the header row before the first record or returns an error.
+// Null and absent values are written as empty cells, character literals using their character
+// format and lists or dicts as JSON.
+func (w *CSVWriter) Write(l lit.Lit) error {
+	if w.cols == nil {
+		w.cols = csvCols(nil, nil, "", w.Type, false)
+		w.row = make([]string, len(w.cols))
+		for i, c := range w.cols {
+			w.row[i] = c.Key
+		}
+		err := w.W.Write(w.row)
+		if err != nil {
+			return err
+		}
+	}
+	for i, c := range w.cols {
+		el, err := lit.SelectPath(l, c.Path)
+		if err != nil {
+			el = nil
+		}
+		w.row[i], err = csvFormat(el)
+		if err != nil {
+			return cor.Errorf("csv column %s: %w", c.Key, err)
+		}
+	}
+	return w.W.Write(w.row)
+}
+
+// WriteAll writes all records in l, flushes the writer and returns an error if any.
+func (w *CSVWriter) WriteAll(l lit.Indexer) error {
+	err := l.IterIdx(func(i int, el lit.Lit) error {
+		return w.Write(el)
+	})
+	if err != nil {
+		return err
+	}
+	w.W.Flush()
+	return w.W.Error()
+}
+
+// Flush flushes the underlying writer and returns an error if any.
+func (w *CSVWriter) Flush() error {
+	w.W.Flush()
+	return w.W.Error()
+}
+
+func csvRecType(t typ.Type) (typ.Type, error) {
+	if t.Kind&typ.MaskElem == typ.KindList {
+		t = t.Elem()
+	}
+	if t.Kind&typ.MaskElem != typ.KindRec || !t.HasParams() {
+		return typ.Void, cor.Errorf("csv expects record or list of records type got %s", t)
+	}
+	return t, nil
+}
+
+// csvCols appends the flattened leaf fields of the record type t to res and returns it.
+func csvCols(res []*csvCol, path lit.Path, pre string, t typ.Type, opt bool) []*csvCol {
+	for _, p := range t.Params {
+		key := pre + p.Key()
+		ft, fopt := p.Type.Deopt()
+		fopt = fopt || opt || p.Opt()
+		fp := append(path[:len(path):len(path)], lit.PathSeg{Key: p.Key()})
+		if ft.Kind&typ.MaskElem == typ.KindRec && ft.HasParams() {
+			res = csvCols(res, fp, key+".", ft, fopt)
+			continue
+		}
+		res = append(res, &csvCol{key, fp, p.Type, fopt})
+	}
+	return res
+}
+
+func findCol(cols []*csvCol, key string) *csvCol {
+	for _, c := range cols {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// csvCell returns the literal of type t parsed from cell or an error.
+func csvCell(cell string, t typ.Type) (lit.Lit, error) {
+	t, _ = t.Deopt()
+	var l lit.Lit = lit.Char(cell)
+	switch k := t.Kind & typ.MaskElem; {
+	case k == typ.KindVoid, k == typ.KindAny:
+		if cell == "" {
+			return lit.Nil, nil
+		}
+		if el, err := lit.Read(strings.NewReader(cell)); err == nil {
+			return el, nil
+		}
+		return l, nil
+	case k&typ.KindChar != 0, k == typ.KindSpan:
+	default:
+		if cell == "" {
+			return nil, cor.Errorf("empty value for %s", t)
+		}
+		el, err := lit.Read(strings.NewReader(cell))
+		if err != nil {
+			return nil, err
+		}
+		l = el
+	}
+	return lit.Convert(l, t, 0)
+}
+
+// csvFormat returns the cell string for l or an error.
+func csvFormat(l lit.Lit) (string, error) {
+	l = lit.Deopt(l)
+	switch v := l.(type) {
+	case nil, lit.Null:
+		return "", nil
+	case lit.Character:
+		return v.Char(), nil
+	case lit.Numeric:
+		return v.String(), nil
+	}
+	b, err := l.MarshalJSON()
+	return string(b), err
+}
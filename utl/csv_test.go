@@ -0,0 +1,122 @@
+package utl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+)
+
+type csvAddr struct {
+	City string
+	Zip  string
+}
+
+type csvRow struct {
+	Name  string
+	Born  time.Time
+	Qty   int
+	Price float64
+	Addr  csvAddr
+	Note  string `json:"note,omitempty"`
+}
+
+func TestCSV(t *testing.T) {
+	raw := "Name,Born,Qty,Price,Addr.City,Addr.Zip,Note\n" +
+		"jane,2024-01-02,3,1.5,Berlin,10115,\n" +
+		"\"doe, john\",2023-12-31,0,0,,,hi\n"
+	var rows []csvRow
+	p, err := prx.NewProxy(&rows)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	r, err := NewCSVReader(strings.NewReader(raw), p.Typ())
+	if err != nil {
+		t.Fatalf("reader err: %v", err)
+	}
+	res, err := r.ReadAll(p.(lit.Appender))
+	if err != nil {
+		t.Fatalf("read all err: %v", err)
+	}
+	err = p.Assign(res)
+	if err != nil {
+		t.Fatalf("assign err: %v", err)
+	}
+	want := []csvRow{
+		{"jane", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 3, 1.5, csvAddr{"Berlin", "10115"}, ""},
+		{"doe, john", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), 0, 0, csvAddr{}, "hi"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("want %d rows got %+v", len(want), rows)
+	}
+	for i := range rows {
+		if !rows[i].Born.Equal(want[i].Born) {
+			t.Errorf("want born %s got %s", want[i].Born, rows[i].Born)
+		}
+		rows[i].Born = want[i].Born
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("want %+v got %+v", want, rows)
+	}
+	var b strings.Builder
+	w, err := NewCSVWriter(&b, p.Typ())
+	if err != nil {
+		t.Fatalf("writer err: %v", err)
+	}
+	err = w.WriteAll(p.(lit.Indexer))
+	if err != nil {
+		t.Fatalf("write all err: %v", err)
+	}
+	wantCSV := "name,born,qty,price,addr.city,addr.zip,note\n" +
+		"jane,2024-01-02T00:00:00Z,3,1.5,Berlin,10115,\n" +
+		"\"doe, john\",2023-12-31T00:00:00Z,0,0,,,hi\n"
+	if got := b.String(); got != wantCSV {
+		t.Errorf("want csv\n%s got\n%s", wantCSV, got)
+	}
+}
+
+func TestCSVErrors(t *testing.T) {
+	var rows []csvRow
+	p, err := prx.NewProxy(&rows)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	tests := []struct {
+		raw, want string
+	}{
+		{"name,born,qty,price,addr.city,addr.zip,extra\n", `csv unknown column "extra"`},
+		{"name,born,price,addr.city,addr.zip\n", `csv missing column "qty"`},
+		{"name\tborn\tqty\tprice\taddr.city\taddr.zip\nx\t2024-01-01\tmany\t0\t\t\n",
+			"csv row 1 column qty"},
+		{"name,born,qty,price,addr.city,addr.zip\nx,2024-01-01,1,0,,,y\n",
+			"csv row 1 column 7"},
+	}
+	for _, test := range tests {
+		r, err := NewTSVReader(strings.NewReader(test.raw), p.Typ())
+		if err != nil {
+			t.Fatalf("reader err: %v", err)
+		}
+		r.R.Comma = ','
+		r.R.FieldsPerRecord = -1
+		if strings.Contains(test.raw, "\t") {
+			r.R.Comma = '\t'
+		}
+		_, err = r.ReadAll(p.(lit.Appender))
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("read %q want error %s got %v", test.raw, test.want, err)
+		}
+	}
+	r, err := NewCSVReader(strings.NewReader(
+		"name,born,qty,price,addr.city,addr.zip,note\nx,2024-01-01,2,0,a,b\n"), p.Typ())
+	if err != nil {
+		t.Fatalf("reader err: %v", err)
+	}
+	r.R.FieldsPerRecord = -1
+	rec, err := r.Read()
+	if err != nil || rec.String() != `{name:'x' born:'2024-01-01T00:00:00Z' qty:2 price:0 addr:{city:'a' zip:'b'}}` {
+		t.Errorf("read short row got %v %v", rec, err)
+	}
+}
@@ -1,7 +1,10 @@
 package utl
 
 import (
+	"strings"
+
 	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/exp"
 	"github.com/mb0/xelf/lit"
 	"github.com/mb0/xelf/typ"
 )
@@ -56,3 +59,179 @@ func ApplyDelta(o lit.Keyer, d *lit.Dict) error {
 	}
 	return nil
 }
+
+// Conflict is a path that was changed differently by two deltas in a three-way merge.
+type Conflict struct {
+	// Path is the shortest delta key of the conflicting changes.
+	Path string
+	// Base is the value at path in the base literal or nil.
+	Base lit.Lit
+	// Ours and Theirs are the values at path after applying each delta.
+	Ours, Theirs lit.Lit
+	// Res is the resolved value or nil if the conflict is unresolved.
+	Res lit.Lit
+}
+
+// MergeStrategy returns the resolution for a conflict, nil to leave it unresolved, or an error.
+type MergeStrategy func(c *Conflict) (lit.Lit, error)
+
+// MergeOurs is a merge strategy that resolves conflicts with our value.
+func MergeOurs(c *Conflict) (lit.Lit, error) { return c.Ours, nil }
+
+// MergeTheirs is a merge strategy that resolves conflicts with their value.
+func MergeTheirs(c *Conflict) (lit.Lit, error) { return c.Theirs, nil }
+
+// MergeExpr returns a merge strategy that evaluates the xelf expression src in env or an error.
+// The conflict is available as parameters $path, $base, $ours and $theirs, for example:
+//    (if (eq $ours null) $theirs $ours)
+func MergeExpr(env exp.Env, src string) (MergeStrategy, error) {
+	_, err := exp.Read(strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return func(c *Conflict) (lit.Lit, error) {
+		x, err := exp.Read(strings.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		param := &lit.Dict{List: []lit.Keyed{
+			{"path", lit.Str(c.Path)},
+			{"base", orNil(c.Base)},
+			{"ours", orNil(c.Ours)},
+			{"theirs", orNil(c.Theirs)},
+		}}
+		el, err := exp.Eval(&exp.ParamEnv{Par: env, Param: param}, x)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := el.(*exp.Atom)
+		if !ok {
+			return nil, cor.Errorf("merge expression %s did not evaluate to a literal", src)
+		}
+		return a.Lit, nil
+	}, nil
+}
+
+// ThreeWayMerge merges the deltas ours and theirs, that were both made against base, and returns
+// the merged delta and all conflicts or an error.
+//
+// Delta keys are paths as used by ApplyDelta. Changes of both deltas conflict if they have
+// the same key or one key is a path prefix of the other, unless both result in equal values.
+// Conflicts are passed to the strategy s, resolved values are added to the merged delta with
+// the conflict path as key. Unresolved conflicts, or all conflicts if s is nil, are left out
+// of the merged delta.
+func ThreeWayMerge(base lit.Lit, ours, theirs *lit.Dict, s MergeStrategy) (*lit.Dict, []*Conflict, error) {
+	type entry struct {
+		lit.Keyed
+		theirs bool
+	}
+	all := make([]entry, 0, ours.Len()+theirs.Len())
+	for _, kv := range ours.List {
+		all = append(all, entry{kv, false})
+	}
+	for _, kv := range theirs.List {
+		all = append(all, entry{kv, true})
+	}
+	// group all entries by the shortest key that is a path prefix of their key
+	var order []string
+	groups := make(map[string][]entry)
+	for _, e := range all {
+		root := e.Key
+		for _, o := range all {
+			if len(o.Key) < len(root) && pathPrefix(o.Key, e.Key) {
+				root = o.Key
+			}
+		}
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], e)
+	}
+	res := &lit.Dict{}
+	var cs []*Conflict
+	for _, root := range order {
+		var mine, their []lit.Keyed
+		for _, e := range groups[root] {
+			if e.theirs {
+				their = append(their, e.Keyed)
+			} else {
+				mine = append(mine, e.Keyed)
+			}
+		}
+		if len(mine) == 0 || len(their) == 0 {
+			res.List = append(res.List, append(mine, their...)...)
+			continue
+		}
+		c := &Conflict{Path: root}
+		c.Base, _ = lit.Select(base, root)
+		var err error
+		c.Ours, err = deltaValue(c.Base, root, mine)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.Theirs, err = deltaValue(c.Base, root, their)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lit.Equal(c.Ours, c.Theirs) {
+			res.List = append(res.List, mine...)
+			continue
+		}
+		cs = append(cs, c)
+		if s == nil {
+			continue
+		}
+		c.Res, err = s(c)
+		if err != nil {
+			return nil, nil, cor.Errorf("resolve conflict %s: %w", root, err)
+		}
+		if c.Res != nil {
+			res.List = append(res.List, lit.Keyed{root, c.Res})
+		}
+	}
+	return res, cs, nil
+}
+
+// deltaValue returns the value at path root after applying the delta entries to base.
+func deltaValue(base lit.Lit, root string, kvs []lit.Keyed) (lit.Lit, error) {
+	var res lit.Lit
+	if base != nil {
+		c, err := lit.Clone(base)
+		if err != nil {
+			return nil, err
+		}
+		res = c
+	} else {
+		res = &lit.Dict{}
+	}
+	for _, kv := range kvs {
+		if kv.Key == root {
+			res = kv.Lit
+			continue
+		}
+		p, err := lit.ReadPath(kv.Key[len(root)+1:])
+		if err != nil {
+			return nil, err
+		}
+		if kv.Key[len(root)] == '/' {
+			p[0].Sel = true
+		}
+		res, err = lit.SetPath(res, p, kv.Lit, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// pathPrefix returns whether path p is a proper path prefix of k.
+func pathPrefix(p, k string) bool {
+	return len(k) > len(p) && strings.HasPrefix(k, p) && (k[len(p)] == '.' || k[len(p)] == '/')
+}
+
+func orNil(l lit.Lit) lit.Lit {
+	if l == nil {
+		return lit.Nil
+	}
+	return l
+}
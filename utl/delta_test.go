@@ -0,0 +1,113 @@
+package utl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+	"github.com/mb0/xelf/std"
+)
+
+type deltaAddr struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type deltaUser struct {
+	Name string    `json:"name"`
+	Age  int64     `json:"age"`
+	Addr deltaAddr `json:"addr"`
+}
+
+func TestApplyTrackedDelta(t *testing.T) {
+	u := deltaUser{Name: "a", Age: 3, Addr: deltaAddr{"x", "1"}}
+	tr, err := prx.Track(&u)
+	if err != nil {
+		t.Fatalf("track err: %v", err)
+	}
+	for _, kv := range []lit.Keyed{
+		{"age", lit.Int(4)},
+		{"name", lit.Str("b")},
+		{"addr.city", lit.Str("y")},
+	} {
+		p, err := lit.ReadPath(kv.Key)
+		if err != nil {
+			t.Fatalf("read path %s err: %v", kv.Key, err)
+		}
+		_, err = lit.SetPath(tr, p, kv.Lit, true)
+		if err != nil {
+			t.Fatalf("set path %s err: %v", kv.Key, err)
+		}
+	}
+	d := tr.Delta()
+	u.Age = 10
+	o := deltaUser{Addr: deltaAddr{"z", "2"}}
+	op, err := prx.NewProxy(&o)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	err = ApplyDelta(op.(lit.Keyer), d)
+	if err != nil {
+		t.Fatalf("apply delta err: %v", err)
+	}
+	want := deltaUser{Name: "b", Age: 4, Addr: deltaAddr{"y", "2"}}
+	if o != want {
+		t.Errorf("want applied delta %+v got %+v", want, o)
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	read := func(s string) lit.Lit {
+		l, err := lit.Read(strings.NewReader(s))
+		if err != nil {
+			t.Fatalf("read %s err: %v", s, err)
+		}
+		return l
+	}
+	base := read(`{id:1 name:'a' tags:['x'] addr:{city:'B' zip:'1'}}`)
+	ours := read(`{name:'b' id:2 'addr.city':'C'}`).(*lit.Dict)
+	theirs := read(`{name:'c' id:2 addr:{city:'D' zip:'2'} tags:['y']}`).(*lit.Dict)
+	tests := []struct {
+		name  string
+		strat MergeStrategy
+		want  string
+	}{
+		{"none", nil, `{id:2 tags:['y']}`},
+		{"ours", MergeOurs, `{name:'b' id:2 addr:{city:'C' zip:'1'} tags:['y']}`},
+		{"theirs", MergeTheirs, `{name:'c' id:2 addr:{city:'D' zip:'2'} tags:['y']}`},
+	}
+	for _, test := range tests {
+		res, cs, err := ThreeWayMerge(base, ours, theirs, test.strat)
+		if err != nil {
+			t.Errorf("%s merge err: %v", test.name, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("%s merge want %s got %s", test.name, test.want, got)
+		}
+		if len(cs) != 2 || cs[0].Path != "name" || cs[1].Path != "addr" {
+			t.Errorf("%s want conflicts name and addr got %v", test.name, cs)
+			continue
+		}
+		if got := cs[1].Ours.String(); got != `{city:'C' zip:'1'}` {
+			t.Errorf("%s want our addr got %s", test.name, got)
+		}
+		if test.strat == nil && cs[0].Res != nil {
+			t.Errorf("%s want unresolved conflict got %s", test.name, cs[0].Res)
+		}
+	}
+	expr, err := MergeExpr(std.Std, `(cat $base ':' $ours '+' $theirs)`)
+	if err != nil {
+		t.Fatalf("merge expr err: %v", err)
+	}
+	ours = read(`{name:'b' id:2}`).(*lit.Dict)
+	theirs = read(`{name:'c'}`).(*lit.Dict)
+	res, cs, err := ThreeWayMerge(base, ours, theirs, expr)
+	if err != nil {
+		t.Fatalf("expr merge err: %v", err)
+	}
+	if want := `{name:'a:b+c' id:2}`; res.String() != want || len(cs) != 1 {
+		t.Errorf("expr merge want %s got %s %v", want, res, cs)
+	}
+}
@@ -0,0 +1,150 @@
+package utl
+
+import (
+	"strings"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// MergeError is an error for a merge patch value at a path.
+type MergeError struct {
+	Path string
+	Err  error
+}
+
+func (e *MergeError) Error() string { return e.Path + ": " + e.Err.Error() }
+func (e *MergeError) Unwrap() error { return e.Err }
+
+// MergeErrors is a list of merge patch errors returned by MergePatch.
+type MergeErrors []*MergeError
+
+func (es MergeErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("merge patch failed")
+	for i, e := range es {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// MergePatch applies the merge patch to l and returns the result or an error.
+//
+// The merge follows RFC 7386: Keyer patches are merged recursively, null values delete dict keys
+// or set record fields to zero and all other values replace the target. Unlike the RFC every value
+// is converted to the type of the target field, so a char literal can be merged into a time field.
+// The target is modified in place, so it works with generic literals and proxies alike. Values that
+// cannot be converted or set are skipped and reported together with their path as MergeErrors.
+func MergePatch(l, patch lit.Lit) (lit.Lit, error) {
+	p, ok := asKeyer(lit.Deopt(patch))
+	if !ok {
+		return replaceRoot(l, patch)
+	}
+	var errs MergeErrors
+	res := mergePatch(l, p, "", &errs)
+	if len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+func mergePatch(l lit.Lit, p lit.Keyer, path string, errs *MergeErrors) lit.Lit {
+	v, ok := asKeyer(lit.Deopt(l))
+	if !ok {
+		v = newKeyer(l)
+	}
+	_, isRec := v.(lit.Record)
+	for _, k := range p.Keys() {
+		kpath := k
+		if path != "" {
+			kpath = path + "." + k
+		}
+		fail := func(err error) {
+			*errs = append(*errs, &MergeError{kpath, err})
+		}
+		el, err := p.Key(k)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		var ft typ.Type
+		if isRec {
+			f, _, err := v.Typ().ParamByKey(k)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			ft = f.Type
+		} else if v.Typ().Kind&typ.MaskElem == typ.KindDict {
+			ft = v.Typ().Elem()
+		}
+		if lit.Deopt(el) == nil {
+			if d, ok := v.(lit.Deleter); ok && !isRec {
+				d.Delete(k)
+				continue
+			}
+			el = lit.Zero(ft)
+		} else if pk, ok := asKeyer(lit.Deopt(el)); ok && isKeyerType(ft) {
+			cur, err := v.Key(k)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			res := mergePatch(cur, pk, kpath, errs)
+			if res == cur {
+				continue
+			}
+			el = res
+		} else if ft != typ.Void && ft != typ.Any {
+			el, err = lit.Convert(el, ft, 0)
+			if err != nil {
+				fail(err)
+				continue
+			}
+		}
+		_, err = v.SetKey(k, el)
+		if err != nil {
+			fail(err)
+		}
+	}
+	if !ok {
+		// assign the new keyer back to proxies or replace generic literals
+		res, err := replaceRoot(l, v)
+		if err != nil {
+			*errs = append(*errs, &MergeError{path, err})
+		}
+		return res
+	}
+	return l
+}
+
+// newKeyer returns a new keyer literal for the type of l or a generic dict.
+func newKeyer(l lit.Lit) lit.Keyer {
+	if l != nil {
+		t, _ := l.Typ().Deopt()
+		switch t.Kind & typ.MaskElem {
+		case typ.KindRec:
+			if r, err := lit.MakeRec(t); err == nil {
+				return r
+			}
+		case typ.KindDict:
+			if d, err := lit.MakeDict(t); err == nil {
+				return d
+			}
+		}
+	}
+	return &lit.Dict{}
+}
+
+func isKeyerType(t typ.Type) bool {
+	switch t.Kind & typ.MaskElem {
+	case typ.KindVoid, typ.KindAny, typ.KindKeyr, typ.KindDict, typ.KindRec, typ.KindObj:
+		return true
+	}
+	return false
+}
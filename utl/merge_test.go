@@ -0,0 +1,141 @@
+package utl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{a:'b'}`, `{a:'c'}`, `{a:'c'}`},
+		{`{a:'b'}`, `{b:'c'}`, `{a:'b' b:'c'}`},
+		{`{a:'b'}`, `{a:null}`, `{}`},
+		{`{a:'b' b:'c'}`, `{a:null}`, `{b:'c'}`},
+		{`{a:['b']}`, `{a:'c'}`, `{a:'c'}`},
+		{`{a:'c'}`, `{a:['b']}`, `{a:['b']}`},
+		{`{a:{b:'c'}}`, `{a:{b:'d' c:null}}`, `{a:{b:'d'}}`},
+		{`{a:[{b:'c'}]}`, `{a:[1]}`, `{a:[1]}`},
+		{`['a' 'b']`, `['c' 'd']`, `['c' 'd']`},
+		{`{a:'b'}`, `['c']`, `['c']`},
+		{`{e:null}`, `{a:1}`, `{e:null a:1}`},
+		{`[1 2]`, `{a:'b' c:null}`, `{a:'b'}`},
+		{`{}`, `{a:{bb:{ccc:null}}}`, `{a:{bb:{}}}`},
+	}
+	for _, test := range tests {
+		target, err := lit.Read(strings.NewReader(test.target))
+		if err != nil {
+			t.Fatalf("read %s err: %v", test.target, err)
+		}
+		patch, err := lit.Read(strings.NewReader(test.patch))
+		if err != nil {
+			t.Fatalf("read %s err: %v", test.patch, err)
+		}
+		res, err := MergePatch(target, patch)
+		if err != nil {
+			t.Errorf("merge %s into %s err: %v", test.patch, test.target, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("merge %s into %s want %s got %s", test.patch, test.target, test.want, got)
+		}
+	}
+}
+
+type mergeAddr struct {
+	City string
+	Zip  string
+}
+
+type mergeUser struct {
+	Name    string
+	Born    time.Time
+	Addr    *mergeAddr
+	Labels  map[string]string
+	Visits  int
+	Comment string `json:"comment,omitempty"`
+}
+
+func TestMergePatchProxy(t *testing.T) {
+	user := mergeUser{Name: "jane", Labels: map[string]string{"a": "1", "b": "2"},
+		Visits: 3, Comment: "x"}
+	p, err := prx.NewProxy(&user)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	patch, err := lit.Read(strings.NewReader(`{
+		"born":"2024-01-01", "addr":{"city":"Berlin"}, "labels":{"a":null,"c":"3"},
+		"comment":null, "visits":"many", "unknown":1
+	}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	_, err = MergePatch(p, patch)
+	var errs MergeErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("want merge errors got %v", err)
+	}
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	if want := []string{"visits", "unknown"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("want error paths %v got %v", want, paths)
+	}
+	want := mergeUser{Name: "jane", Born: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Addr: &mergeAddr{City: "Berlin"}, Labels: map[string]string{"b": "2", "c": "3"},
+		Visits: 3}
+	if !user.Born.Equal(want.Born) {
+		t.Errorf("want born %s got %s", want.Born, user.Born)
+	}
+	user.Born = want.Born
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("want %+v got %+v", want, user)
+	}
+}
+
+func TestMergePatchNonKeyer(t *testing.T) {
+	var v struct {
+		Meta interface{}
+		Tags []string
+	}
+	v.Tags = []string{"x"}
+	p, err := prx.NewProxy(&v)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	patch, err := lit.Read(strings.NewReader(`{"meta":{"a":1}, "tags":{"b":2}}`))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	_, err = MergePatch(p, patch)
+	var errs MergeErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "tags" {
+		t.Fatalf("want merge error for tags got %v", err)
+	}
+	if m, ok := v.Meta.(lit.Lit); !ok || m.String() != "{a:1}" {
+		t.Errorf("want meta dict got %#v", v.Meta)
+	}
+	if !reflect.DeepEqual(v.Tags, []string{"x"}) {
+		t.Errorf("want tags unchanged got %v", v.Tags)
+	}
+	var any interface{}
+	p, err = prx.NewProxy(&any)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	res, err := MergePatch(p, patch)
+	if err != nil {
+		t.Fatalf("merge err: %v", err)
+	}
+	if res != p || any == nil {
+		t.Errorf("want result assigned to proxy got %v %#v", res, any)
+	}
+}
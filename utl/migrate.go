@@ -0,0 +1,243 @@
+package utl
+
+import (
+	"strings"
+
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/exp"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// MigrateStep describes how one field of the new record type is computed from an old value.
+type MigrateStep struct {
+	// Key is the field key in the new type or the dropped key in the old type.
+	Key string
+	// Src is the field key in the old type or empty if the field is new.
+	Src string
+	// Type is the field type in the new type.
+	Type typ.Type
+	// Cmp is the comparison result from the old to the new field type.
+	Cmp typ.Cmp
+	// Def is the default literal used for new fields and zero values of required fields.
+	Def lit.Lit
+	// Drop indicates a field of the old type without counterpart in the new type.
+	Drop bool
+}
+
+// Checked returns whether the step requires a conversion that can fail for some values.
+func (s MigrateStep) Checked() bool { return s.Src != "" && s.Cmp < typ.LvlConv }
+
+func (s MigrateStep) String() string {
+	switch {
+	case s.Drop:
+		return "drop " + s.Key
+	case s.Src == "":
+		return "add " + s.Key + " default " + s.Def.String()
+	}
+	var b strings.Builder
+	if s.Src != s.Key {
+		b.WriteString("rename ")
+		b.WriteString(s.Src)
+		b.WriteString(" to ")
+	} else if s.Cmp != typ.CmpSame {
+		b.WriteString("convert ")
+	} else {
+		b.WriteString("keep ")
+	}
+	b.WriteString(s.Key)
+	if s.Cmp != typ.CmpSame {
+		b.WriteString(" as ")
+		b.WriteString(s.Type.String())
+	}
+	if s.Checked() {
+		b.WriteString(" checked")
+	}
+	return b.String()
+}
+
+// Migration is a plan to transform literals of one record type to another record type.
+type Migration struct {
+	From, To typ.Type
+	Steps    []MigrateStep
+}
+
+// NewMigration returns a migration plan from the old to the new record type or an error.
+// The renames map old field keys to new field keys. The defaults map new field keys to literals
+// used for new fields or zero values of required fields, new fields use the zero value otherwise.
+func NewMigration(from, to typ.Type, renames map[string]string, defs map[string]lit.Lit) (*Migration, error) {
+	if from.Kind&typ.MaskElem != typ.KindRec || !from.HasParams() {
+		return nil, cor.Errorf("migrate from %s: %w", from, typ.ErrInvalid)
+	}
+	if to.Kind&typ.MaskElem != typ.KindRec || !to.HasParams() {
+		return nil, cor.Errorf("migrate to %s: %w", to, typ.ErrInvalid)
+	}
+	srcs := make(map[string]string, len(from.Params))
+	for _, p := range from.Params {
+		key := p.Key()
+		if n, ok := renames[key]; ok {
+			srcs[n] = key
+		} else if _, ok := srcs[key]; !ok {
+			srcs[key] = key
+		}
+	}
+	for old := range renames {
+		if _, _, err := from.ParamByKey(old); err != nil {
+			return nil, cor.Errorf("migrate rename: %w", err)
+		}
+	}
+	m := &Migration{From: from, To: to, Steps: make([]MigrateStep, 0, len(to.Params))}
+	used := make(map[string]bool, len(to.Params))
+	for _, p := range to.Params {
+		s := MigrateStep{Key: p.Key(), Type: p.Type, Def: defs[p.Key()]}
+		if s.Def != nil {
+			d, err := lit.Convert(s.Def, p.Type, 0)
+			if err != nil {
+				return nil, cor.Errorf("migrate default for %s: %w", s.Key, err)
+			}
+			s.Def = d
+		}
+		if src, ok := srcs[s.Key]; ok {
+			f, _, _ := from.ParamByKey(src)
+			s.Src, s.Cmp = src, typ.Compare(f.Type, p.Type)
+			if s.Cmp < typ.LvlCheck {
+				return nil, cor.Errorf("migrate field %s: incompatible %s to %s",
+					s.Key, f.Type, p.Type)
+			}
+			used[src] = true
+		} else if s.Def == nil {
+			s.Def = lit.Zero(p.Type)
+		}
+		m.Steps = append(m.Steps, s)
+	}
+	for _, p := range from.Params {
+		if key := p.Key(); !used[key] {
+			m.Steps = append(m.Steps, MigrateStep{Key: key, Drop: true})
+		}
+	}
+	return m, nil
+}
+
+// Migrate returns a new record literal of the new type migrated from l or an error.
+func (m *Migration) Migrate(l lit.Lit) (lit.Lit, error) {
+	res, err := lit.MakeRec(m.To)
+	if err != nil {
+		return nil, err
+	}
+	err = m.migrate(l, res, func(s MigrateStep, err error) error {
+		return cor.Errorf("migrate field %s: %w", s.Key, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m.To.Kind&typ.KindOpt != 0 {
+		return lit.Some{res}, nil
+	}
+	return res, nil
+}
+
+func (m *Migration) migrate(l lit.Lit, res *lit.Rec, fail func(MigrateStep, error) error) error {
+	k, ok := lit.Deopt(l).(lit.Keyer)
+	if !ok {
+		return cor.Errorf("migrate expects keyer got %s", l.Typ())
+	}
+	for _, s := range m.Steps {
+		if s.Drop {
+			continue
+		}
+		el := s.Def
+		if s.Src != "" {
+			v, err := k.Key(s.Src)
+			if err != nil {
+				if err = fail(s, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if v == nil || s.Def == nil || !v.IsZero() {
+				el, err = lit.Convert(v, s.Type, 0)
+				if err != nil {
+					if err = fail(s, err); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+		}
+		_, err := res.SetKey(s.Key, el)
+		if err != nil {
+			if err = fail(s, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MigrateFailure is a failed checked field conversion of the element at idx.
+type MigrateFailure struct {
+	Idx int
+	Key string
+	Err error
+}
+
+// MigrateReport is the result of a migration dry-run.
+type MigrateReport struct {
+	// Total is the number of inspected literals.
+	Total int
+	// Failed holds all conversion failures ordered by index.
+	Failed []MigrateFailure
+}
+
+// DryRun migrates each element of the list without keeping the results and returns a report of all
+// failed conversions or an error.
+func (m *Migration) DryRun(list lit.Indexer) (*MigrateReport, error) {
+	res := &MigrateReport{}
+	err := list.IterIdx(func(i int, el lit.Lit) error {
+		res.Total++
+		tmp, err := lit.MakeRec(m.To)
+		if err != nil {
+			return err
+		}
+		return m.migrate(el, tmp, func(s MigrateStep, err error) error {
+			res.Failed = append(res.Failed, MigrateFailure{i, s.Key, err})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Expr returns a xelf expression constructing the new type from an old value in the dot scope.
+// Fields with a default use it for zero old values, like Migrate does. The expression can be used
+// to review or store the plan and evaluated using a with expression:
+//    (with old (<rec name:str> name:.title))
+func (m *Migration) Expr() exp.El {
+	els := make([]exp.El, 0, len(m.Steps)+1)
+	els = append(els, &exp.Atom{Lit: m.To})
+	for _, s := range m.Steps {
+		var el exp.El
+		switch {
+		case s.Drop:
+			continue
+		case s.Src == "":
+			el = &exp.Atom{Lit: s.Def}
+		default:
+			src := &exp.Sym{Name: "." + s.Src}
+			el = src
+			if s.Cmp < typ.LvlComp {
+				el = &exp.Dyn{Els: []exp.El{&exp.Atom{Lit: s.Type}, el}}
+			}
+			if s.Def != nil {
+				el = &exp.Dyn{Els: []exp.El{&exp.Sym{Name: "if"}, src, el,
+					&exp.Atom{Lit: s.Def}}}
+			}
+		}
+		els = append(els, &exp.Tag{Name: s.Key, El: el})
+	}
+	return &exp.Dyn{Els: els}
+}
+
+func (m *Migration) String() string { return m.Expr().String() }
@@ -0,0 +1,90 @@
+package utl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/exp"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/std"
+	"github.com/mb0/xelf/typ"
+)
+
+func TestMigration(t *testing.T) {
+	from, err := typ.Read(strings.NewReader(`<rec id:int title:str created:char old:bool>`))
+	if err != nil {
+		t.Fatalf("read from type: %v", err)
+	}
+	to, err := typ.Read(strings.NewReader(`<rec id:int name:str created:time score:int>`))
+	if err != nil {
+		t.Fatalf("read to type: %v", err)
+	}
+	m, err := NewMigration(from, to, map[string]string{"title": "name"},
+		map[string]lit.Lit{"score": lit.Num(10), "name": lit.Str("unnamed")})
+	if err != nil {
+		t.Fatalf("new migration: %v", err)
+	}
+	want := `(<rec id:int name:str created:time score:int> ` +
+		`id:.id name:(if .title .title 'unnamed') created:(time .created) score:10)`
+	if got := m.String(); got != want {
+		t.Errorf("want expr %s got %s", want, got)
+	}
+	steps := []string{"keep id", "rename title to name", "convert created as time checked",
+		"add score default 10", "drop old"}
+	for i, s := range m.Steps {
+		if got := s.String(); got != steps[i] {
+			t.Errorf("want step %s got %s", steps[i], got)
+		}
+	}
+	for _, test := range []struct{ raw, want string }{
+		{`{id:1 title:'foo' created:'2019-01-17' old:true}`,
+			`{id:1 name:'foo' created:'2019-01-17T00:00:00Z' score:10}`},
+		{`{id:2 title:'' created:'2019-01-17' old:true}`,
+			`{id:2 name:'unnamed' created:'2019-01-17T00:00:00Z' score:10}`},
+	} {
+		old, err := lit.Read(strings.NewReader(test.raw))
+		if err != nil {
+			t.Fatalf("read old: %v", err)
+		}
+		old, err = lit.Convert(old, from, 0)
+		if err != nil {
+			t.Fatalf("convert old: %v", err)
+		}
+		res, err := m.Migrate(old)
+		if err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("want migrated %s got %s", test.want, got)
+		}
+		env := exp.NewScope(std.Std)
+		env.Def("old", &exp.Def{Type: from, Lit: old})
+		x := &exp.Dyn{Els: []exp.El{&exp.Sym{Name: "with"}, &exp.Sym{Name: "old"}, m.Expr()}}
+		el, err := exp.Eval(env, x)
+		if err != nil {
+			t.Fatalf("eval expr: %v", err)
+		}
+		if got := el.String(); got != test.want {
+			t.Errorf("want evaluated %s got %s", test.want, got)
+		}
+	}
+	bad, _ := lit.Read(strings.NewReader(`[{id:1 title:'a' created:'2019-01-17'}
+		{id:2 title:'b' created:'yesterday'}]`))
+	other, err := lit.MakeRec(typ.Rec([]typ.Param{{Name: "id", Type: typ.Int}}))
+	if err != nil {
+		t.Fatalf("make rec: %v", err)
+	}
+	bad.(*lit.List).Data = append(bad.(*lit.List).Data, other)
+	rep, err := m.DryRun(bad.(lit.Indexer))
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if rep.Total != 3 || len(rep.Failed) != 3 {
+		t.Fatalf("want three failures in three got %+v", rep)
+	}
+	for i, want := range []MigrateFailure{{1, "created", nil}, {2, "name", nil}, {2, "created", nil}} {
+		if f := rep.Failed[i]; f.Idx != want.Idx || f.Key != want.Key {
+			t.Errorf("want failure for %d %s got %+v", want.Idx, want.Key, f)
+		}
+	}
+}
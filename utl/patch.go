@@ -0,0 +1,592 @@
+package utl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mb0/xelf/bfr"
+	"github.com/mb0/xelf/cor"
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/typ"
+)
+
+// PatchOp is a JSON Patch operation as specified in RFC 6902.
+// Path and From are JSON pointers as specified in RFC 6901.
+type PatchOp struct {
+	Op    string
+	Path  string
+	From  string
+	Value lit.Lit
+}
+
+func (o PatchOp) String() string { return bfr.String(o) }
+func (o PatchOp) WriteBfr(b *bfr.Ctx) error {
+	b.WriteByte('{')
+	b.RecordKey("op")
+	b.Quote(o.Op)
+	if o.From != "" || o.Op == "move" || o.Op == "copy" {
+		b.Sep()
+		b.RecordKey("from")
+		b.Quote(o.From)
+	}
+	b.Sep()
+	b.RecordKey("path")
+	b.Quote(o.Path)
+	switch o.Op {
+	case "add", "replace", "test":
+		b.Sep()
+		b.RecordKey("value")
+		if o.Value == nil {
+			b.Fmt("null")
+		} else if err := o.Value.WriteBfr(b); err != nil {
+			return err
+		}
+	}
+	return b.WriteByte('}')
+}
+
+// Patch is a list of patch operations that is serialized as JSON Patch document.
+type Patch []PatchOp
+
+func (p Patch) String() string               { return bfr.String(p) }
+func (p Patch) MarshalJSON() ([]byte, error) { return bfr.JSON(p) }
+func (p Patch) WriteBfr(b *bfr.Ctx) error {
+	b.WriteByte('[')
+	for i, o := range p {
+		if i > 0 {
+			b.Sep()
+		}
+		if err := o.WriteBfr(b); err != nil {
+			return err
+		}
+	}
+	return b.WriteByte(']')
+}
+
+// ReadPatch returns the patch for a literal list of operation keyers or an error.
+func ReadPatch(l lit.Lit) (Patch, error) {
+	v, ok := lit.Deopt(l).(lit.Indexer)
+	if !ok {
+		return nil, cor.Errorf("patch expects list got %s", l)
+	}
+	res := make(Patch, 0, v.Len())
+	err := v.IterIdx(func(i int, el lit.Lit) error {
+		k, ok := lit.Deopt(el).(lit.Keyer)
+		if !ok {
+			return cor.Errorf("patch op %d expects keyer got %s", i, el)
+		}
+		var o PatchOp
+		for _, key := range k.Keys() {
+			v, err := k.Key(key)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "op", "path", "from":
+				c, ok := lit.Deopt(v).(lit.Character)
+				if !ok {
+					return cor.Errorf("patch op %d %s expects char got %s", i, key, v)
+				}
+				switch key {
+				case "op":
+					o.Op = c.Char()
+				case "path":
+					o.Path = c.Char()
+				default:
+					o.From = c.Char()
+				}
+			case "value":
+				o.Value = v
+			}
+		}
+		res = append(res, o)
+		return nil
+	})
+	return res, err
+}
+
+// Diff returns a patch that transforms literal a into b or an error.
+//
+// Keyer literals are compared key by key and lists are compared element wise based on their
+// longest common subsequence. List elements are removed, added, moved or replaced. Nested
+// containers of the same kind are diffed recursively.
+func Diff(a, b lit.Lit) (Patch, error) {
+	return diff(nil, "", a, b)
+}
+
+func diff(res Patch, ptr string, a, b lit.Lit) (Patch, error) {
+	a, b = lit.Deopt(a), lit.Deopt(b)
+	if lit.Equal(a, b) {
+		return res, nil
+	}
+	if ak, ok := asKeyer(a); ok {
+		if bk, ok := asKeyer(b); ok {
+			return diffKeyer(res, ptr, ak, bk)
+		}
+	} else if al, ok := asList(a); ok {
+		if bl, ok := asList(b); ok {
+			return diffList(res, ptr, al, bl)
+		}
+	}
+	return append(res, PatchOp{Op: "replace", Path: ptr, Value: b}), nil
+}
+
+func diffKeyer(res Patch, ptr string, a, b lit.Keyer) (_ Patch, err error) {
+	bkeys := b.Keys()
+	bset := make(map[string]bool, len(bkeys))
+	for _, k := range bkeys {
+		bset[k] = true
+	}
+	aset := make(map[string]bool, len(bkeys))
+	for _, k := range a.Keys() {
+		aset[k] = true
+		if !bset[k] {
+			res = append(res, PatchOp{Op: "remove", Path: ptr + "/" + escapePointer(k)})
+			continue
+		}
+		av, err := a.Key(k)
+		if err != nil {
+			return nil, err
+		}
+		bv, err := b.Key(k)
+		if err != nil {
+			return nil, err
+		}
+		res, err = diff(res, ptr+"/"+escapePointer(k), av, bv)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range bkeys {
+		if aset[k] {
+			continue
+		}
+		bv, err := b.Key(k)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, PatchOp{Op: "add", Path: ptr + "/" + escapePointer(k), Value: bv})
+	}
+	return res, nil
+}
+
+// maxLCS is the maximum product of list lengths that are compared with a full lcs table.
+const maxLCS = 1 << 20
+
+func diffList(res Patch, ptr string, a, b []lit.Lit) (_ Patch, err error) {
+	akeep, bkeep := lcs(a, b)
+	cur := append([]lit.Lit(nil), a...)
+	keep := append([]bool(nil), akeep...)
+	for j := 0; j < len(b); j++ {
+		if j < len(cur) && lit.Equal(cur[j], b[j]) {
+			continue
+		}
+		path := ptr + "/" + strconv.Itoa(j)
+		if j < len(cur) && !keep[j] && bkeep[j] {
+			// the current element is not part of the result
+			res = append(res, PatchOp{Op: "remove", Path: path})
+			cur = append(cur[:j], cur[j+1:]...)
+			keep = append(keep[:j], keep[j+1:]...)
+			j--
+			continue
+		}
+		if k := findUnkept(cur, keep, j+1, b[j]); k >= 0 {
+			res = append(res, PatchOp{Op: "move", From: ptr + "/" + strconv.Itoa(k), Path: path})
+			el := cur[k]
+			cur = append(cur[:k], cur[k+1:]...)
+			keep = append(keep[:k], keep[k+1:]...)
+			cur = append(cur[:j], append([]lit.Lit{el}, cur[j:]...)...)
+			keep = append(keep[:j], append([]bool{true}, keep[j:]...)...)
+			continue
+		}
+		if j < len(cur) && !keep[j] {
+			res, err = diff(res, path, cur[j], b[j])
+			if err != nil {
+				return nil, err
+			}
+			cur[j], keep[j] = b[j], true
+			continue
+		}
+		res = append(res, PatchOp{Op: "add", Path: path, Value: b[j]})
+		cur = append(cur[:j], append([]lit.Lit{b[j]}, cur[j:]...)...)
+		keep = append(keep[:j], append([]bool{true}, keep[j:]...)...)
+	}
+	for i := len(cur) - 1; i >= len(b); i-- {
+		res = append(res, PatchOp{Op: "remove", Path: ptr + "/" + strconv.Itoa(i)})
+	}
+	return res, nil
+}
+
+func findUnkept(cur []lit.Lit, keep []bool, start int, el lit.Lit) int {
+	for k := start; k < len(cur); k++ {
+		if !keep[k] && lit.Equal(cur[k], el) {
+			return k
+		}
+	}
+	return -1
+}
+
+// lcs returns flags for elements of a and b that are part of the longest common subsequence.
+// Only common prefix and suffix are used for lists too large for the lcs table.
+func lcs(a, b []lit.Lit) (akeep, bkeep []bool) {
+	akeep, bkeep = make([]bool, len(a)), make([]bool, len(b))
+	pre := 0
+	for pre < len(a) && pre < len(b) && lit.Equal(a[pre], b[pre]) {
+		akeep[pre], bkeep[pre] = true, true
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && lit.Equal(a[len(a)-1-suf], b[len(b)-1-suf]) {
+		akeep[len(a)-1-suf], bkeep[len(b)-1-suf] = true, true
+		suf++
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	n, m := len(ma), len(mb)
+	if n == 0 || m == 0 || n*m > maxLCS {
+		return akeep, bkeep
+	}
+	// t[i][j] holds the lcs length of ma[i:] and mb[j:]
+	t := make([][]int, n+1)
+	for i := range t {
+		t[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if lit.Equal(ma[i], mb[j]) {
+				t[i][j] = t[i+1][j+1] + 1
+			} else if t[i+1][j] >= t[i][j+1] {
+				t[i][j] = t[i+1][j]
+			} else {
+				t[i][j] = t[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case lit.Equal(ma[i], mb[j]):
+			akeep[pre+i], bkeep[pre+j] = true, true
+			i++
+			j++
+		case t[i+1][j] >= t[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return akeep, bkeep
+}
+
+func asKeyer(l lit.Lit) (lit.Keyer, bool) {
+	k, ok := l.(lit.Keyer)
+	return k, ok && l.Typ().Kind&typ.MaskElem != typ.KindList
+}
+
+func asList(l lit.Lit) ([]lit.Lit, bool) {
+	v, ok := l.(lit.Indexer)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := l.(lit.Keyer); ok {
+		return nil, false
+	}
+	res := make([]lit.Lit, 0, v.Len())
+	err := v.IterIdx(func(_ int, el lit.Lit) error {
+		res = append(res, el)
+		return nil
+	})
+	return res, err == nil
+}
+
+// Apply applies the patch to l and returns the result or an error.
+//
+// Generic literals and proxies are modified in place where possible, the returned literal must
+// be used for generic literals. Values are converted to the type of the target containers. The
+// patch is not atomic, an error leaves l with all previous operations applied.
+func (p Patch) Apply(l lit.Lit) (_ lit.Lit, err error) {
+	for i, o := range p {
+		l, err = applyOp(l, o)
+		if err != nil {
+			return l, cor.Errorf("patch op %d %s %s: %w", i, o.Op, o.Path, err)
+		}
+	}
+	return l, nil
+}
+
+func applyOp(l lit.Lit, o PatchOp) (lit.Lit, error) {
+	switch o.Op {
+	case "add":
+		return patchAdd(l, o.Path, o.Value)
+	case "remove":
+		_, l, err := patchRemove(l, o.Path)
+		return l, err
+	case "replace":
+		path, par, err := locate(l, o.Path)
+		if err != nil {
+			return l, err
+		}
+		if len(path) == 0 {
+			return replaceRoot(l, o.Value)
+		}
+		if _, err = lit.SelectPath(l, path); err != nil {
+			return l, err
+		}
+		// keyers return null for missing keys, but replace must not add keys
+		if k, ok := lit.Deopt(par).(lit.Keyer); ok && !hasKey(k, path[len(path)-1].Key) {
+			return l, cor.Errorf("key %q not found", path[len(path)-1].Key)
+		}
+		return lit.SetPath(l, path, o.Value, false)
+	case "move", "copy":
+		path, _, err := locate(l, o.From)
+		if err != nil {
+			return l, err
+		}
+		el, err := lit.SelectPath(l, path)
+		if err != nil {
+			return l, err
+		}
+		el, err = lit.Clone(el)
+		if err != nil {
+			return l, err
+		}
+		if o.Op == "move" {
+			if strings.HasPrefix(o.Path, o.From+"/") {
+				return l, cor.Errorf("cannot move %s into itself", o.From)
+			}
+			_, l, err = patchRemove(l, o.From)
+			if err != nil {
+				return l, err
+			}
+		}
+		return patchAdd(l, o.Path, el)
+	case "test":
+		path, _, err := locate(l, o.Path)
+		if err != nil {
+			return l, err
+		}
+		el, err := lit.SelectPath(l, path)
+		if err != nil {
+			return l, err
+		}
+		if !lit.Equiv(el, o.Value) {
+			return l, cor.Errorf("test failed want %s got %s", o.Value, el)
+		}
+		return l, nil
+	}
+	return l, cor.Errorf("unknown patch op %q", o.Op)
+}
+
+func patchAdd(l lit.Lit, ptr string, val lit.Lit) (lit.Lit, error) {
+	path, par, err := locate(l, ptr)
+	if err != nil {
+		return l, err
+	}
+	if len(path) == 0 {
+		return replaceRoot(l, val)
+	}
+	last := path[len(path)-1]
+	if last.Key != "" {
+		return lit.SetPath(l, path, val, false)
+	}
+	v, ok := lit.Deopt(par).(lit.Indexer)
+	if !ok {
+		return l, cor.Errorf("add expects list got %s", par)
+	}
+	res, err := insertIdx(v, last.Idx, val)
+	if err != nil {
+		return l, err
+	}
+	return setParent(l, path, res)
+}
+
+func patchRemove(l lit.Lit, ptr string) (el, _ lit.Lit, err error) {
+	path, par, err := locate(l, ptr)
+	if err != nil {
+		return nil, l, err
+	}
+	if len(path) == 0 {
+		return nil, l, cor.Error("cannot remove root")
+	}
+	el, err = lit.SelectPath(l, path)
+	if err != nil {
+		return nil, l, err
+	}
+	last := path[len(path)-1]
+	var res lit.Lit
+	switch v := lit.Deopt(par).(type) {
+	case lit.Record:
+		// record fields cannot be removed and are set to zero instead
+		f, _, err := v.Typ().ParamByKey(last.Key)
+		if err != nil {
+			return nil, l, err
+		}
+		_, err = v.SetKey(last.Key, lit.Zero(f.Type))
+		return el, l, err
+	case lit.Deleter:
+		if !v.Delete(last.Key) {
+			return nil, l, cor.Errorf("key %q not found", last.Key)
+		}
+		res = v
+	case lit.Indexer:
+		res, err = removeIdx(v, last.Idx)
+		if err != nil {
+			return nil, l, err
+		}
+	default:
+		return nil, l, cor.Errorf("remove expects container got %s", par)
+	}
+	l, err = setParent(l, path, res)
+	return el, l, err
+}
+
+// hasKey returns whether the keyer k contains key.
+func hasKey(k lit.Keyer, key string) bool {
+	for _, n := range k.Keys() {
+		if n == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setParent sets the parent container at path, that might have been changed, back into l.
+func setParent(l lit.Lit, path lit.Path, par lit.Lit) (lit.Lit, error) {
+	if len(path) == 1 {
+		if lit.Deopt(l) == par {
+			return l, nil
+		}
+		return replaceRoot(l, par)
+	}
+	return lit.SetPath(l, path[:len(path)-1], par, false)
+}
+
+// replaceRoot assigns val to proxies l or returns val for generic literals.
+func replaceRoot(l, val lit.Lit) (lit.Lit, error) {
+	switch l.(type) {
+	case *lit.Dict, *lit.List, *lit.Rec:
+		return val, nil
+	}
+	if p, ok := l.(lit.Proxy); ok {
+		val, err := lit.Convert(val, p.Typ(), 0)
+		if err != nil {
+			return l, err
+		}
+		return p, p.Assign(val)
+	}
+	return val, nil
+}
+
+func insertIdx(v lit.Indexer, idx int, el lit.Lit) (lit.Lit, error) {
+	n := v.Len()
+	if idx > n {
+		return v, lit.ErrIdxBounds
+	}
+	a, ok := v.(lit.Appender)
+	if !ok {
+		return v, cor.Errorf("cannot add to %s", v.Typ())
+	}
+	if idx == n {
+		return a.Append(el)
+	}
+	last, err := v.Idx(n - 1)
+	if err != nil {
+		return v, err
+	}
+	last, err = lit.Clone(last)
+	if err != nil {
+		return v, err
+	}
+	if a, err = a.Append(last); err != nil {
+		return v, err
+	}
+	for i := n - 1; i > idx; i-- {
+		prev, err := a.Idx(i - 1)
+		if err != nil {
+			return v, err
+		}
+		if _, err = a.SetIdx(i, prev); err != nil {
+			return v, err
+		}
+	}
+	_, err = a.SetIdx(idx, el)
+	return a, err
+}
+
+func removeIdx(v lit.Indexer, idx int) (lit.Lit, error) {
+	n := v.Len()
+	if idx < 0 || idx >= n {
+		return v, lit.ErrIdxBounds
+	}
+	for i := idx; i < n-1; i++ {
+		next, err := v.Idx(i + 1)
+		if err != nil {
+			return v, err
+		}
+		if _, err = v.SetIdx(i, next); err != nil {
+			return v, err
+		}
+	}
+	if l, ok := v.(*lit.List); ok {
+		l.Data = l.Data[:n-1]
+		return l, nil
+	}
+	p, ok := v.(lit.Proxy)
+	if !ok {
+		return v, cor.Errorf("cannot remove from %s", v.Typ())
+	}
+	res := &lit.List{Elem: v.Typ().Elem(), Data: make([]lit.Lit, 0, n-1)}
+	for i := 0; i < n-1; i++ {
+		el, err := v.Idx(i)
+		if err != nil {
+			return v, err
+		}
+		res.Data = append(res.Data, el)
+	}
+	return p, p.Assign(res)
+}
+
+// locate returns the path for a JSON pointer resolved against l and the parent literal of the
+// last path segment or an error. Segments select by key from keyers and by index from lists,
+// the special index '-' is resolved to the list length.
+func locate(l lit.Lit, ptr string) (res lit.Path, par lit.Lit, err error) {
+	if ptr == "" {
+		return nil, nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, nil, cor.Errorf("invalid json pointer %q", ptr)
+	}
+	segs := strings.Split(ptr[1:], "/")
+	res = make(lit.Path, 0, len(segs))
+	for i, s := range segs {
+		s = unescapePointer(s)
+		var seg lit.PathSeg
+		switch v := lit.Deopt(l).(type) {
+		case lit.Keyer:
+			if s == "" {
+				return nil, nil, cor.Errorf("empty key in json pointer %q", ptr)
+			}
+			seg.Key = s
+		case lit.Indexer:
+			if s == "-" {
+				seg.Idx = v.Len()
+			} else if seg.Idx, err = strconv.Atoi(s); err != nil || seg.Idx < 0 {
+				return nil, nil, cor.Errorf("invalid index %q in json pointer %q", s, ptr)
+			}
+		default:
+			return nil, nil, cor.Errorf("json pointer %q expects container got %s", ptr, l)
+		}
+		res, par = append(res, seg), l
+		if i < len(segs)-1 {
+			l, err = lit.SelectPath(l, lit.Path{seg})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return res, par, nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func escapePointer(key string) string   { return pointerEscaper.Replace(key) }
+func unescapePointer(seg string) string { return pointerUnescaper.Replace(seg) }
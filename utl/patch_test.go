@@ -0,0 +1,143 @@
+package utl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mb0/xelf/lit"
+	"github.com/mb0/xelf/prx"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{`1`, `1`, `[]`},
+		{`{a:1}`, `{a:2}`, `[{op:'replace' path:'/a' value:2}]`},
+		{`{a:1 b:2}`, `{b:2 c:3}`, `[{op:'remove' path:'/a'} {op:'add' path:'/c' value:3}]`},
+		{`{'a/b':{'c~':1}}`, `{'a/b':{'c~':2}}`, `[{op:'replace' path:'/a~1b/c~0' value:2}]`},
+		{`[1 2 3]`, `[1 3]`, `[{op:'remove' path:'/1'}]`},
+		{`[1 2 3]`, `[1 2 3 4]`, `[{op:'add' path:'/3' value:4}]`},
+		{`[1 2 3]`, `[3 1 2]`, `[{op:'move' from:'/2' path:'/0'}]`},
+		{`[1 {a:1} 3]`, `[1 {a:2} 3]`, `[{op:'replace' path:'/1/a' value:2}]`},
+		{`[1 2 3 4]`, `[0 2 5]`, `[{op:'replace' path:'/0' value:0} ` +
+			`{op:'replace' path:'/2' value:5} {op:'remove' path:'/3'}]`},
+		{`{a:[1 2]}`, `{a:'x'}`, `[{op:'replace' path:'/a' value:'x'}]`},
+	}
+	for _, test := range tests {
+		a, err := lit.Read(strings.NewReader(test.a))
+		if err != nil {
+			t.Fatalf("read %s err: %v", test.a, err)
+		}
+		b, err := lit.Read(strings.NewReader(test.b))
+		if err != nil {
+			t.Fatalf("read %s err: %v", test.b, err)
+		}
+		p, err := Diff(a, b)
+		if err != nil {
+			t.Errorf("diff %s %s err: %v", test.a, test.b, err)
+			continue
+		}
+		if got := p.String(); got != test.want {
+			t.Errorf("diff %s %s want %s got %s", test.a, test.b, test.want, got)
+		}
+		res, err := p.Apply(a)
+		if err != nil {
+			t.Errorf("apply %s to %s err: %v", p, test.a, err)
+			continue
+		}
+		if !lit.Equal(res, b) {
+			t.Errorf("apply %s want %s got %s", p, b, res)
+		}
+	}
+}
+
+type patchItem struct {
+	Name string
+	Qty  int
+}
+
+type patchKey string
+
+type patchOrder struct {
+	ID    int
+	Items []patchItem
+	Tags  map[patchKey]string
+}
+
+func TestPatchProxy(t *testing.T) {
+	order := patchOrder{1, []patchItem{{"a", 1}, {"b", 2}, {"c", 3}}, map[patchKey]string{"x": "1"}}
+	raw := `[
+		{"op":"test","path":"/items/1/name","value":"b"},
+		{"op":"add","path":"/items/1","value":{"name":"d","qty":4}},
+		{"op":"remove","path":"/items/0"},
+		{"op":"move","from":"/items/1","path":"/items/-"},
+		{"op":"copy","from":"/items/0","path":"/items/0"},
+		{"op":"replace","path":"/items/0/qty","value":5},
+		{"op":"add","path":"/tags/y","value":"2"},
+		{"op":"remove","path":"/tags/x"},
+		{"op":"replace","path":"/id","value":2}
+	]`
+	l, err := lit.Read(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("read err: %v", err)
+	}
+	p, err := ReadPatch(l)
+	if err != nil {
+		t.Fatalf("read patch err: %v", err)
+	}
+	pl, err := prx.NewProxy(&order)
+	if err != nil {
+		t.Fatalf("proxy err: %v", err)
+	}
+	_, err = p.Apply(pl)
+	if err != nil {
+		t.Fatalf("apply err: %v", err)
+	}
+	want := patchOrder{2, []patchItem{{"d", 5}, {"d", 4}, {"c", 3}, {"b", 2}},
+		map[patchKey]string{"y": "2"}}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("want %+v got %+v", want, order)
+	}
+	// diff two go values and apply the result to a proxy
+	other := patchOrder{3, []patchItem{{"c", 3}, {"d", 4}, {"e", 6}}, map[patchKey]string{"z": "3"}}
+	ol, err := prx.Adapt(other)
+	if err != nil {
+		t.Fatalf("adapt err: %v", err)
+	}
+	p, err = Diff(pl, ol)
+	if err != nil {
+		t.Fatalf("diff err: %v", err)
+	}
+	_, err = p.Apply(pl)
+	if err != nil {
+		t.Fatalf("apply %s err: %v", p, err)
+	}
+	if !reflect.DeepEqual(order, other) {
+		t.Errorf("want %+v got %+v", other, order)
+	}
+	_, err = Patch{{Op: "test", Path: "/id", Value: lit.Num(1)}}.Apply(pl)
+	if err == nil {
+		t.Errorf("want failed test error")
+	}
+	_, err = Patch{{Op: "replace", Path: "/tags/missing", Value: lit.Str("x")}}.Apply(pl)
+	if err == nil || len(order.Tags) != 1 {
+		t.Errorf("want replace of missing key error got %v", order.Tags)
+	}
+	d := &lit.Dict{List: []lit.Keyed{{"a", lit.Num(1)}}}
+	_, err = Patch{{Op: "replace", Path: "/b", Value: lit.Num(2)}}.Apply(d)
+	if err == nil || d.Len() != 1 {
+		t.Errorf("want replace of missing dict key error got %s", d)
+	}
+	// copied lists must not share elements with the source
+	d = &lit.Dict{List: []lit.Keyed{{"a", &lit.List{Data: []lit.Lit{lit.Num(1)}}}}}
+	res, err := Patch{
+		{Op: "copy", From: "/a", Path: "/b"},
+		{Op: "replace", Path: "/b/0", Value: lit.Num(2)},
+	}.Apply(d)
+	if err != nil || res.String() != `{a:[1] b:[2]}` {
+		t.Errorf("want copied list to be independent got %s %v", res, err)
+	}
+}